@@ -0,0 +1,89 @@
+// Package main demonstrates the circuit breaker of the xerrs breaker package.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hotfixfirst/go-xerrs"
+	"github.com/hotfixfirst/go-xerrs/breaker"
+)
+
+func main() {
+	fmt.Println("=== Circuit Breaker Examples ===")
+	fmt.Println()
+
+	// A manual clock makes the open timeout deterministic.
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := breaker.New(&breaker.Options{
+		FailureThreshold: 2,
+		OpenTimeout:      30 * time.Second,
+		Now:              func() time.Time { return now },
+		OnStateChange: func(name string, from, to breaker.State) {
+			fmt.Printf("  %s: %s -> %s\n", name, from, to)
+		},
+	})
+	ctx := context.Background()
+
+	failing := func(ctx context.Context) error {
+		return xerrs.New("billing unavailable").AsExternalServiceUnavailable()
+	}
+	succeeding := func(ctx context.Context) error { return nil }
+
+	// Example 1: Client errors do not count as failures
+	fmt.Println("1. Client Errors Do Not Count")
+	fmt.Println("-----------------------------")
+	for range 3 {
+		_ = b.Do(ctx, "billing", func(ctx context.Context) error {
+			return xerrs.New("invoice 7 not found").AsResourceNotFound()
+		})
+	}
+	fmt.Printf("State: %s\n", b.State("billing"))
+	fmt.Println()
+
+	// Example 2: Consecutive external failures open the circuit
+	fmt.Println("2. External Failures Open the Circuit")
+	fmt.Println("-------------------------------------")
+	for range 2 {
+		_ = b.Do(ctx, "billing", failing)
+	}
+	fmt.Printf("State: %s\n", b.State("billing"))
+	fmt.Println()
+
+	// Example 3: An open circuit fails fast
+	fmt.Println("3. Failing Fast")
+	fmt.Println("---------------")
+	now = now.Add(10 * time.Second)
+	err := b.Do(ctx, "billing", succeeding)
+	appErr, _ := xerrs.AsAppError(err)
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("Is open: %t\n", breaker.IsOpen(err))
+	fmt.Printf("Retry after: %s\n", appErr.RetryAfter())
+	fmt.Println()
+
+	// Example 4: Circuits are kept per dependency
+	fmt.Println("4. One Circuit per Dependency")
+	fmt.Println("-----------------------------")
+	err = b.Do(ctx, "search", succeeding)
+	fmt.Printf("search: %v, state %s\n", err, b.State("search"))
+	fmt.Println()
+
+	// Example 5: A successful trial call closes the circuit
+	fmt.Println("5. Recovery")
+	fmt.Println("-----------")
+	now = now.Add(30 * time.Second)
+	err = b.Do(ctx, "billing", succeeding)
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("State: %s\n", b.State("billing"))
+	fmt.Println()
+
+	// Example 6: The type and code used for the decision
+	fmt.Println("6. Classification")
+	fmt.Println("-----------------")
+	errorType, code := xerrs.Classify(xerrs.New("gateway timeout").AsServiceTimeout())
+	fmt.Printf("Type: %s, Code: %s\n", errorType, code)
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
@@ -0,0 +1,106 @@
+// Package main demonstrates converting upstream HTTP responses into AppErrors.
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func main() {
+	fmt.Println("=== HTTP Client Examples ===")
+	fmt.Println()
+
+	// A fake upstream answering with a different failure per path.
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			fmt.Fprint(w, "ok")
+		case "/unavailable":
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		case "/throttled":
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case "/missing":
+			http.Error(w, "no such user", http.StatusNotFound)
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"type":"about:blank","title":"Conflict","status":409,"detail":"email already taken"}`)
+		}
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: xerrs.NewTransport(nil, &xerrs.TransportOptions{
+		Service:             "users",
+		PassthroughNotFound: true,
+	})}
+
+	get := func(path string) {
+		resp, err := client.Get(upstream.URL + path)
+		if err != nil {
+			appErr, _ := xerrs.AsAppError(err)
+			fmt.Printf("Error: %v\n", appErr)
+			if u, ok := appErr.Upstream(); ok {
+				fmt.Printf("Upstream: %s %s (status %d)\n", u.Service, u.Endpoint, u.Status)
+			}
+			if after := appErr.RetryAfter(); after > 0 {
+				fmt.Printf("Retry after: %s\n", after)
+			}
+			if cause, ok := xerrs.AsAppError(appErr.Unwrap()); ok {
+				fmt.Printf("Cause: %s %s: %s\n", cause.Type, cause.Code, cause.Message)
+			}
+			return
+		}
+		defer resp.Body.Close()
+		fmt.Printf("Status: %d\n", resp.StatusCode)
+	}
+
+	// Example 1: Successful responses pass through
+	fmt.Println("1. Successful Response")
+	fmt.Println("----------------------")
+	get("/ok")
+	fmt.Println()
+
+	// Example 2: Upstream 5xx becomes an external error
+	fmt.Println("2. Upstream Unavailable")
+	fmt.Println("-----------------------")
+	get("/unavailable")
+	fmt.Println()
+
+	// Example 3: 429 keeps its Retry-After hint
+	fmt.Println("3. Rate Limited")
+	fmt.Println("---------------")
+	get("/throttled")
+	fmt.Println()
+
+	// Example 4: 404 passes through as not found
+	fmt.Println("4. Passthrough Not Found")
+	fmt.Println("------------------------")
+	get("/missing")
+	fmt.Println()
+
+	// Example 5: Problem details bodies are decoded as the cause
+	fmt.Println("5. Problem Details Body")
+	fmt.Println("-----------------------")
+	get("/problem")
+	fmt.Println()
+
+	// Example 6: DecodeResponse for a response obtained elsewhere
+	fmt.Println("6. DecodeResponse")
+	fmt.Println("-----------------")
+	resp, err := http.Get(upstream.URL + "/unavailable")
+	if err != nil {
+		fmt.Printf("Request failed: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	err = xerrs.DecodeResponse(resp, &xerrs.TransportOptions{Service: "users"})
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("HTTP status for our caller: %d\n", xerrs.ToAppError(err).HTTPStatus)
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
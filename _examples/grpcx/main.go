@@ -0,0 +1,125 @@
+// Package main demonstrates carrying xerrs errors across gRPC with grpcx.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/hotfixfirst/go-xerrs"
+	"github.com/hotfixfirst/go-xerrs/grpcx"
+)
+
+// healthServer fails the health check of each service with a different error.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "users":
+		return nil, xerrs.New("user 42 not found").AsResourceNotFound()
+	case "billing":
+		return nil, xerrs.New("billing is overloaded").
+			AsExternalServiceUnavailable().
+			WithDetails("queue depth 1200").
+			WithRetryAfter(5 * time.Second)
+	case "search":
+		return nil, errors.New("index corrupted")
+	case "legacy":
+		return nil, status.Error(codes.PermissionDenied, "legacy service is private")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func main() {
+	fmt.Println("=== gRPC Examples ===")
+	fmt.Println()
+
+	// An in-memory server and client, each with the grpcx interceptors.
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcx.UnaryServerInterceptor()))
+	grpc_health_v1.RegisterHealthServer(server, healthServer{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcx.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		fmt.Printf("Dial failed: %v\n", err)
+		return
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	check := func(service string) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err == nil {
+			fmt.Println("Serving")
+			return
+		}
+		appErr, _ := xerrs.AsAppError(err)
+		fmt.Printf("Error: %v\n", appErr)
+		fmt.Printf("gRPC code: %s, HTTP status: %d\n", status.Code(err), appErr.HTTPStatus)
+		if appErr.Details != "" {
+			fmt.Printf("Details: %s\n", appErr.Details)
+		}
+		if after := appErr.RetryAfter(); after > 0 {
+			fmt.Printf("Retry after: %s\n", after)
+		}
+	}
+
+	// Example 1: Successful calls are unchanged
+	fmt.Println("1. Successful Call")
+	fmt.Println("------------------")
+	check("")
+	fmt.Println()
+
+	// Example 2: Type and code survive the round trip
+	fmt.Println("2. Not Found")
+	fmt.Println("------------")
+	check("users")
+	fmt.Println()
+
+	// Example 3: Details and RetryAfter are sent as status details
+	fmt.Println("3. Details and Retry Info")
+	fmt.Println("-------------------------")
+	check("billing")
+	fmt.Println()
+
+	// Example 4: Plain errors are classified on the server
+	fmt.Println("4. Plain Error")
+	fmt.Println("--------------")
+	check("search")
+	fmt.Println()
+
+	// Example 5: Statuses from other servers are classified by code
+	fmt.Println("5. Foreign Status")
+	fmt.Println("-----------------")
+	check("legacy")
+	fmt.Println()
+
+	// Example 6: Converting without interceptors
+	fmt.Println("6. ToStatus and FromStatus")
+	fmt.Println("--------------------------")
+	st := grpcx.ToStatus(xerrs.New("too many requests").AsTooManyRequests())
+	fmt.Printf("Status: %s %q, details: %d\n", st.Code(), st.Message(), len(st.Details()))
+	fmt.Printf("Back: %v\n", grpcx.FromStatus(st))
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
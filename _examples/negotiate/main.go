@@ -0,0 +1,95 @@
+// Package main demonstrates content negotiation and versioned error schemas.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func main() {
+	fmt.Println("=== Error Response Examples ===")
+	fmt.Println()
+
+	// A handler that always fails with a validation error. The request headers
+	// (">") select the response format and, for JSON, the schema version of the
+	// response ("<").
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := xerrs.New("invalid signup").
+			AsInvalidInput().
+			WithFieldError("email", "required", "email is required").
+			WithFingerprint("signup-validation")
+		xerrs.RenderError(w, r, err)
+	})
+
+	serve := func(header ...string) {
+		req := httptest.NewRequest(http.MethodPost, "/signup", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+			fmt.Printf("> %s: %s\n", header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		fmt.Printf("< Status: %d\n", rec.Code)
+		fmt.Printf("< Content-Type: %s\n", rec.Header().Get("Content-Type"))
+		if version := rec.Header().Get(xerrs.ErrorSchemaHeader); version != "" {
+			fmt.Printf("< %s: %s\n", xerrs.ErrorSchemaHeader, version)
+		}
+		fmt.Printf("< %s\n", strings.TrimSpace(rec.Body.String()))
+	}
+
+	// Example 1: Problem details by default
+	fmt.Println("1. Problem Details (Default)")
+	fmt.Println("----------------------------")
+	serve()
+	fmt.Println()
+
+	// Example 2: Plain text for clients that ask for it
+	fmt.Println("2. Plain Text")
+	fmt.Println("-------------")
+	serve("Accept", "text/plain")
+	fmt.Println()
+
+	// Example 3: JSON in the default schema version
+	fmt.Println("3. JSON Schema Version 1")
+	fmt.Println("------------------------")
+	serve("Accept", "application/json")
+	fmt.Println()
+
+	// Example 4: Selecting schema version 2 with a header
+	fmt.Println("4. JSON Schema Version 2 (Header)")
+	fmt.Println("---------------------------------")
+	serve("Accept", "application/json", xerrs.ErrorSchemaHeader, "2")
+	fmt.Println()
+
+	// Example 5: Selecting schema version 2 with an Accept parameter
+	fmt.Println("5. JSON Schema Version 2 (Accept Parameter)")
+	fmt.Println("-------------------------------------------")
+	serve("Accept", "application/json; version=2")
+	fmt.Println()
+
+	// Example 6: Encoding and decoding without HTTP
+	fmt.Println("6. Encode and Decode")
+	fmt.Println("--------------------")
+	data, err := xerrs.EncodeErrorResponse(errors.New("disk full"), 2)
+	if err != nil {
+		fmt.Printf("Encode failed: %v\n", err)
+		return
+	}
+	fmt.Printf("Encoded: %s\n", data)
+	decoded, err := xerrs.DecodeErrorResponse(data)
+	if err != nil {
+		fmt.Printf("Decode failed: %v\n", err)
+		return
+	}
+	fmt.Printf("Decoded: %v (status %d)\n", decoded, decoded.HTTPStatus)
+	_, err = xerrs.EncodeErrorResponse(decoded, 9)
+	fmt.Printf("Unknown version: %v\n", err)
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
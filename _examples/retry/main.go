@@ -0,0 +1,99 @@
+// Package main demonstrates the retry executor of the xerrs package.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// policy retries quickly so that the example runs fast.
+var policy = xerrs.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     50 * time.Millisecond,
+}
+
+// flaky returns a function that fails with the given errors, one per call,
+// and then succeeds.
+func flaky(errs ...error) func(ctx context.Context) error {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		fmt.Printf("  attempt %d\n", calls)
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}
+}
+
+func main() {
+	fmt.Println("=== Retry Examples ===")
+	fmt.Println()
+
+	unavailable := xerrs.New("payments unavailable").AsExternalServiceUnavailable()
+	notFound := xerrs.New("order 42 not found").AsResourceNotFound()
+
+	// Example 1: Retryable errors are retried until the call succeeds
+	fmt.Println("1. Success After Retries")
+	fmt.Println("------------------------")
+	err := xerrs.Retry(context.Background(), policy, flaky(unavailable, unavailable))
+	fmt.Printf("Error: %v\n", err)
+	fmt.Println()
+
+	// Example 2: Retry gives up after MaxAttempts
+	fmt.Println("2. Giving Up")
+	fmt.Println("------------")
+	err = xerrs.Retry(context.Background(), policy, flaky(unavailable, unavailable, unavailable))
+	appErr, _ := xerrs.AsAppError(err)
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("Attempts: %d\n", appErr.Attempts())
+	fmt.Println()
+
+	// Example 3: Errors that are not retryable stop at once
+	fmt.Println("3. Permanent Error")
+	fmt.Println("------------------")
+	err = xerrs.Retry(context.Background(), policy, flaky(notFound))
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("Unchanged: %t\n", err == error(notFound))
+	fmt.Println()
+
+	// Example 4: A permanent error after retries records the attempts
+	fmt.Println("4. Permanent Error After Retries")
+	fmt.Println("--------------------------------")
+	err = xerrs.Retry(context.Background(), policy, flaky(unavailable, notFound))
+	appErr, _ = xerrs.AsAppError(err)
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("Attempts: %d\n", appErr.Attempts())
+	fmt.Printf("Is not found: %t\n", errors.Is(err, notFound))
+	fmt.Println()
+
+	// Example 5: Retry stops when the context is done
+	fmt.Println("5. Canceled Context")
+	fmt.Println("-------------------")
+	ctx, cancel := context.WithCancel(context.Background())
+	err = xerrs.Retry(ctx, policy, func(ctx context.Context) error {
+		cancel()
+		return unavailable
+	})
+	fmt.Printf("Error: %v\n", err)
+	fmt.Printf("Is canceled: %t\n", errors.Is(err, context.Canceled))
+	fmt.Println()
+
+	// Example 6: A custom classifier decides what is retried
+	fmt.Println("6. Custom Classifier")
+	fmt.Println("--------------------")
+	custom := policy
+	custom.Classifier = func(err error) bool {
+		return xerrs.IsRetryable(err) || errors.Is(err, notFound)
+	}
+	err = xerrs.Retry(context.Background(), custom, flaky(notFound))
+	fmt.Printf("Error: %v\n", err)
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
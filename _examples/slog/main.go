@@ -0,0 +1,69 @@
+// Package main demonstrates the log/slog integration of the xerrs package.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func main() {
+	fmt.Println("=== Structured Logging Examples ===")
+	fmt.Println()
+
+	// The time is dropped so that the output is stable.
+	text := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := slog.New(xerrs.NewSlogHandler(text, nil))
+
+	// Example 1: An AppError is logged as a group of its fields
+	fmt.Println("1. AppError as a Group")
+	fmt.Println("----------------------")
+	notFound := xerrs.New("user 42 not found").AsResourceNotFound()
+	logger.Info("lookup failed", "error", notFound)
+	fmt.Println()
+
+	// Example 2: Client errors raise the level to WARN
+	fmt.Println("2. Client Errors Raise to WARN")
+	fmt.Println("------------------------------")
+	invalid := xerrs.New("email is required").AsRequiredField()
+	logger.Debug("request rejected", "error", invalid)
+	fmt.Println()
+
+	// Example 3: Server errors raise the level to ERROR
+	fmt.Println("3. Server Errors Raise to ERROR")
+	fmt.Println("-------------------------------")
+	dbErr := xerrs.New("connection refused").AsDatabaseConnection()
+	logger.Info("query failed", "error", dbErr)
+	fmt.Println()
+
+	// Example 4: Errors attached with With raise every record
+	fmt.Println("4. Errors Attached with With")
+	fmt.Println("----------------------------")
+	requestLogger := logger.With("error", dbErr)
+	requestLogger.Info("responding")
+	fmt.Println()
+
+	// Example 5: Wrapped errors keep their full message
+	fmt.Println("5. Wrapped AppErrors")
+	fmt.Println("--------------------")
+	logger.Info("sync failed", "error", fmt.Errorf("sync users: %w", notFound))
+	fmt.Println()
+
+	// Example 6: Keep the record level
+	fmt.Println("6. Keep the Record Level")
+	fmt.Println("------------------------")
+	keepLevel := slog.New(xerrs.NewSlogHandler(text, &xerrs.SlogHandlerOptions{KeepLevel: true}))
+	keepLevel.Info("query failed", "error", dbErr)
+	fmt.Println()
+
+	fmt.Println("=== End of Examples ===")
+}
@@ -0,0 +1,282 @@
+// Package breaker implements circuit breakers keyed by dependency name that
+// trip on xerrs errors classified as external, unavailable or timeout
+// failures.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// Default values applied to zero fields of Options.
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHalfOpenMaxCalls = 1
+)
+
+// ErrOpen is the cause of the errors returned while a circuit rejects calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit.
+type State int
+
+// Circuit states.
+const (
+	// StateClosed lets calls through and counts consecutive failures.
+	StateClosed State = iota
+	// StateOpen rejects calls until the open timeout elapses.
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through.
+	StateHalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Options configures a Breaker. Zero fields use the defaults.
+type Options struct {
+	// FailureThreshold is the number of consecutive failures that opens a circuit.
+	FailureThreshold int
+	// OpenTimeout is how long a circuit stays open before it lets trial calls through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of trial calls allowed while half-open.
+	// The circuit closes once that many of them succeed.
+	HalfOpenMaxCalls int
+	// IsFailure reports whether an error counts as a failure. Defaults to IsFailure.
+	IsFailure func(err error) bool
+	// OnStateChange, when set, is called after a circuit changes state. It runs
+	// with the breaker locked and must not call back into the Breaker.
+	OnStateChange func(name string, from, to State)
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// IsFailure reports whether err counts against a dependency: errors of the
+// external or unavailable types and external timeouts. Validation, not found
+// and other errors caused by the caller do not count, nor do context
+// cancellation and the expiry of the caller's own deadline.
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	errorType, code := xerrs.Classify(err)
+	switch errorType {
+	case xerrs.ErrorTypeExternal, xerrs.ErrorTypeUnavailable, xerrs.ErrorTypeGatewayTimeout:
+		return true
+	}
+	return code == xerrs.CodeExternalTimeout
+}
+
+// callerGaveUp reports whether err means the caller abandoned the call, which
+// says nothing about the dependency.
+func callerGaveUp(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	_, code := xerrs.Classify(err)
+	return code == xerrs.CodeInternalTimeout
+}
+
+// IsOpen reports whether err was returned because a circuit rejected the call.
+func IsOpen(err error) bool {
+	return errors.Is(err, ErrOpen)
+}
+
+// circuit is the state of a single dependency.
+type circuit struct {
+	state      State
+	failures   int
+	successes  int
+	inFlight   int
+	openedAt   time.Time
+	generation uint64
+}
+
+// Breaker tracks one circuit per dependency name. It is safe for concurrent use.
+type Breaker struct {
+	mu       sync.Mutex
+	opts     Options
+	circuits map[string]*circuit
+}
+
+// New creates a Breaker. A nil opts uses the defaults.
+func New(opts *Options) *Breaker {
+	b := &Breaker{circuits: make(map[string]*circuit)}
+	if opts != nil {
+		b.opts = *opts
+	}
+	if b.opts.FailureThreshold <= 0 {
+		b.opts.FailureThreshold = DefaultFailureThreshold
+	}
+	if b.opts.OpenTimeout <= 0 {
+		b.opts.OpenTimeout = DefaultOpenTimeout
+	}
+	if b.opts.HalfOpenMaxCalls <= 0 {
+		b.opts.HalfOpenMaxCalls = DefaultHalfOpenMaxCalls
+	}
+	if b.opts.IsFailure == nil {
+		b.opts.IsFailure = IsFailure
+	}
+	if b.opts.Now == nil {
+		b.opts.Now = time.Now
+	}
+	return b
+}
+
+// Do calls fn through the circuit of the named dependency. While the circuit
+// is open, Do returns an *AppError with CodeExternalUnavailable whose
+// RetryAfter is the remaining open duration, without calling fn. Otherwise it
+// returns the error of fn unchanged. When fn panics, its call is released
+// without counting as a success or a failure.
+func (b *Breaker) Do(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	generation, err := b.allow(name)
+	if err != nil {
+		return err
+	}
+	returned := false
+	defer func() {
+		if !returned {
+			b.release(name, generation)
+		}
+	}()
+	err = fn(ctx)
+	returned = true
+	b.done(name, generation, err)
+	return err
+}
+
+// State returns the current state of the named circuit.
+func (b *Breaker) State(name string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[name]
+	if !ok {
+		return StateClosed
+	}
+	b.refreshLocked(name, c)
+	return c.state
+}
+
+// Reset closes the named circuit and clears its counters.
+func (b *Breaker) Reset(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[name]; ok {
+		b.setStateLocked(name, c, StateClosed)
+	}
+}
+
+// allow reserves a call on the named circuit, or returns the error to fail fast with.
+func (b *Breaker) allow(name string) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{}
+		b.circuits[name] = c
+	}
+	b.refreshLocked(name, c)
+
+	switch c.state {
+	case StateOpen:
+		remaining := c.openedAt.Add(b.opts.OpenTimeout).Sub(b.opts.Now())
+		return 0, openError(name, remaining)
+	case StateHalfOpen:
+		if c.inFlight >= b.opts.HalfOpenMaxCalls {
+			return 0, openError(name, 0)
+		}
+	}
+	c.inFlight++
+	return c.generation, nil
+}
+
+// done records the outcome of a call reserved by allow. Outcomes of calls
+// started before the last state change are ignored.
+func (b *Breaker) done(name string, generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[name]
+	if c.generation != generation {
+		return
+	}
+	c.inFlight--
+
+	switch {
+	case b.opts.IsFailure(err):
+		c.failures++
+		if c.state == StateHalfOpen || c.failures >= b.opts.FailureThreshold {
+			b.setStateLocked(name, c, StateOpen)
+		}
+	case callerGaveUp(err):
+	default:
+		c.failures = 0
+		if c.state == StateHalfOpen {
+			c.successes++
+			if c.successes >= b.opts.HalfOpenMaxCalls {
+				b.setStateLocked(name, c, StateClosed)
+			}
+		}
+	}
+}
+
+// release frees a call reserved by allow without recording an outcome.
+func (b *Breaker) release(name string, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c := b.circuits[name]; c.generation == generation {
+		c.inFlight--
+	}
+}
+
+// refreshLocked moves an open circuit to half-open once its timeout elapsed.
+func (b *Breaker) refreshLocked(name string, c *circuit) {
+	if c.state == StateOpen && !b.opts.Now().Before(c.openedAt.Add(b.opts.OpenTimeout)) {
+		b.setStateLocked(name, c, StateHalfOpen)
+	}
+}
+
+// setStateLocked changes the state of a circuit and resets its counters.
+func (b *Breaker) setStateLocked(name string, c *circuit, state State) {
+	from := c.state
+	c.state = state
+	c.failures, c.successes, c.inFlight = 0, 0, 0
+	c.generation++
+	if state == StateOpen {
+		c.openedAt = b.opts.Now()
+	}
+	if b.opts.OnStateChange != nil && from != state {
+		b.opts.OnStateChange(name, from, state)
+	}
+}
+
+// openErrors creates the errors of rejected calls. They are returned on the
+// fast path and all share the same origin, so no stack trace is captured.
+var openErrors = xerrs.WithStackPolicy(xerrs.StackPolicy{Capture: xerrs.StackCaptureNever})
+
+// openError returns the error for a call rejected by the named circuit.
+func openError(name string, remaining time.Duration) *xerrs.AppError {
+	if remaining < 0 {
+		remaining = 0
+	}
+	return openErrors.Wrap(ErrOpen, fmt.Sprintf("circuit breaker for %s is open", name)).
+		AsExternalServiceUnavailable().
+		WithDetails(fmt.Sprintf("retry in %s", remaining)).
+		WithRetryAfter(remaining)
+}
@@ -0,0 +1,217 @@
+package breaker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// testClock is a manually advanced time source.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(opts Options) (*Breaker, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	opts.Now = clock.Now
+	return New(&opts), clock
+}
+
+func call(b *Breaker, name string, err error) error {
+	return b.Do(context.Background(), name, func(context.Context) error { return err })
+}
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"External", xerrs.New("x").AsExternalWithCode(xerrs.CodeExternalError), true},
+		{"Unavailable", xerrs.New("x").AsServiceUnavailable(), true},
+		{"External Timeout", xerrs.New("x").AsServiceTimeout(), true},
+		{"Gateway Timeout", xerrs.New("x").AsGatewayTimeout(), true},
+		{"Deadline Exceeded", context.DeadlineExceeded, false},
+		{"Internal Timeout", xerrs.New("x").AsInternalWithCode(xerrs.CodeInternalTimeout), false},
+		{"Connection Refused", errors.New("dial tcp: connection refused"), true},
+		{"Validation", xerrs.New("x").AsValidationError(), false},
+		{"Not Found", sql.ErrNoRows, false},
+		{"Canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsFailure(tt.err))
+		})
+	}
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 3, OpenTimeout: 10 * time.Second})
+	failure := xerrs.New("upstream down").AsExternalWithCode(xerrs.CodeExternalError)
+
+	for range 2 {
+		assert.Equal(t, failure, call(b, "billing", failure))
+	}
+	assert.Equal(t, StateClosed, b.State("billing"))
+	call(b, "billing", failure)
+	assert.Equal(t, StateOpen, b.State("billing"))
+	assert.Equal(t, StateClosed, b.State("search"))
+
+	clock.Advance(4 * time.Second)
+	called := false
+	err := b.Do(context.Background(), "billing", func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.False(t, called)
+	assert.True(t, IsOpen(err))
+
+	appErr, ok := xerrs.AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, xerrs.CodeExternalUnavailable, appErr.Code)
+	assert.Equal(t, 6*time.Second, appErr.RetryAfter())
+	assert.Equal(t, "retry in 6s", appErr.Details)
+	assert.Contains(t, appErr.Message, "billing")
+}
+
+func TestBreaker_IgnoresClientErrors(t *testing.T) {
+	b, _ := newTestBreaker(Options{FailureThreshold: 2})
+
+	for range 5 {
+		call(b, "users", xerrs.New("bad").AsValidationError())
+		call(b, "users", xerrs.New("gone").AsResourceNotFound())
+	}
+	assert.Equal(t, StateClosed, b.State("users"))
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(Options{FailureThreshold: 2})
+	failure := xerrs.New("timeout").AsServiceTimeout()
+
+	call(b, "users", failure)
+	call(b, "users", nil)
+	call(b, "users", failure)
+	assert.Equal(t, StateClosed, b.State("users"))
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 1, OpenTimeout: time.Second})
+	failure := xerrs.New("down").AsServiceUnavailable()
+
+	call(b, "users", failure)
+	clock.Advance(time.Second)
+	assert.Equal(t, StateHalfOpen, b.State("users"))
+
+	// A failed trial call reopens the circuit.
+	call(b, "users", failure)
+	assert.Equal(t, StateOpen, b.State("users"))
+
+	// A successful trial call closes it.
+	clock.Advance(time.Second)
+	require.NoError(t, call(b, "users", nil))
+	assert.Equal(t, StateClosed, b.State("users"))
+}
+
+func TestBreaker_HalfOpenLimitsTrialCalls(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 1, OpenTimeout: time.Second})
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+	clock.Advance(time.Second)
+
+	err := b.Do(context.Background(), "users", func(context.Context) error {
+		assert.True(t, IsOpen(call(b, "users", nil)))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StateClosed, b.State("users"))
+}
+
+func TestBreaker_CanceledIsNeutral(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 1, OpenTimeout: time.Second})
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+	clock.Advance(time.Second)
+
+	call(b, "users", context.Canceled)
+	assert.Equal(t, StateHalfOpen, b.State("users"))
+}
+
+func TestBreaker_DeadlineExceededIsNeutral(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 1, OpenTimeout: time.Second})
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+	clock.Advance(time.Second)
+
+	call(b, "users", context.DeadlineExceeded)
+	assert.Equal(t, StateHalfOpen, b.State("users"))
+}
+
+func TestBreaker_PanicReleasesHalfOpenCall(t *testing.T) {
+	b, clock := newTestBreaker(Options{FailureThreshold: 1, OpenTimeout: time.Second})
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+	clock.Advance(time.Second)
+
+	assert.Panics(t, func() {
+		_ = b.Do(context.Background(), "users", func(context.Context) error { panic("boom") })
+	})
+	assert.Equal(t, StateHalfOpen, b.State("users"))
+
+	require.NoError(t, call(b, "users", nil))
+	assert.Equal(t, StateClosed, b.State("users"))
+}
+
+func TestBreaker_OpenErrorWithoutStack(t *testing.T) {
+	b, _ := newTestBreaker(Options{FailureThreshold: 1})
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+
+	appErr, ok := xerrs.AsAppError(call(b, "users", nil))
+	require.True(t, ok)
+	assert.Empty(t, appErr.StackFrames())
+}
+
+func TestBreaker_StaleOutcomesIgnored(t *testing.T) {
+	b, _ := newTestBreaker(Options{FailureThreshold: 1})
+
+	err := b.Do(context.Background(), "users", func(context.Context) error {
+		b.Reset("users")
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = b.Do(context.Background(), "users", func(context.Context) error {
+		call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StateOpen, b.State("users"))
+}
+
+func TestBreaker_OnStateChange(t *testing.T) {
+	var transitions []string
+	b, clock := newTestBreaker(Options{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Second,
+		OnStateChange: func(name string, from, to State) {
+			transitions = append(transitions, name+": "+from.String()+" -> "+to.String())
+		},
+	})
+
+	call(b, "users", xerrs.New("down").AsExternalWithCode(xerrs.CodeExternalError))
+	clock.Advance(time.Second)
+	call(b, "users", nil)
+
+	assert.Equal(t, []string{
+		"users: closed -> open",
+		"users: open -> half-open",
+		"users: half-open -> closed",
+	}, transitions)
+}
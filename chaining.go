@@ -125,6 +125,16 @@ func (e *AppError) AsDatabaseConstraint() *AppError {
 	return e.AsInternalWithCode(CodeDatabaseConstraint)
 }
 
+// AsDatabaseDeadlock converts the error to a database deadlock error.
+func (e *AppError) AsDatabaseDeadlock() *AppError {
+	return e.AsInternalWithCode(CodeDatabaseDeadlock)
+}
+
+// AsDatabaseSerialization converts the error to a database serialization failure error.
+func (e *AppError) AsDatabaseSerialization() *AppError {
+	return e.AsInternalWithCode(CodeDatabaseSerialization)
+}
+
 // AsUnavailableWithCode converts the error to an unavailable service error with a specific code.
 func (e *AppError) AsUnavailableWithCode(code string) *AppError {
 	return e.setTypeAndStatus(ErrorTypeUnavailable).WithCode(code)
@@ -170,6 +180,56 @@ func (e *AppError) AsTimeout() *AppError {
 	return e.AsInternalWithCode(CodeInternalTimeout)
 }
 
+// AsMethodNotAllowed converts the error to a method not allowed error.
+func (e *AppError) AsMethodNotAllowed() *AppError {
+	return e.setTypeAndStatus(ErrorTypeMethodNotAllowed).WithCode(CodeMethodNotAllowed)
+}
+
+// AsRequestTimeout converts the error to a request timeout error.
+func (e *AppError) AsRequestTimeout() *AppError {
+	return e.setTypeAndStatus(ErrorTypeRequestTimeout).WithCode(CodeRequestTimeout)
+}
+
+// AsGone converts the error to a gone error for resources that no longer exist.
+func (e *AppError) AsGone() *AppError {
+	return e.setTypeAndStatus(ErrorTypeGone).WithCode(CodeResourceGone)
+}
+
+// AsPreconditionFailed converts the error to a precondition failed error.
+func (e *AppError) AsPreconditionFailed() *AppError {
+	return e.setTypeAndStatus(ErrorTypePreconditionFailed).WithCode(CodePreconditionFailed)
+}
+
+// AsPayloadTooLarge converts the error to a payload too large error.
+func (e *AppError) AsPayloadTooLarge() *AppError {
+	return e.setTypeAndStatus(ErrorTypePayloadTooLarge).WithCode(CodePayloadTooLarge)
+}
+
+// AsUnsupportedMediaType converts the error to an unsupported media type error.
+func (e *AppError) AsUnsupportedMediaType() *AppError {
+	return e.setTypeAndStatus(ErrorTypeUnsupportedMediaType).WithCode(CodeUnsupportedMediaType)
+}
+
+// AsUnprocessableEntity converts the error to an unprocessable entity error.
+func (e *AppError) AsUnprocessableEntity() *AppError {
+	return e.setTypeAndStatus(ErrorTypeUnprocessable).WithCode(CodeUnprocessableEntity)
+}
+
+// AsClientClosed converts the error to a client closed request error.
+func (e *AppError) AsClientClosed() *AppError {
+	return e.setTypeAndStatus(ErrorTypeClientClosed).WithCode(CodeClientClosedRequest)
+}
+
+// AsNotImplemented converts the error to a not implemented error.
+func (e *AppError) AsNotImplemented() *AppError {
+	return e.setTypeAndStatus(ErrorTypeNotImplemented).WithCode(CodeNotImplemented)
+}
+
+// AsGatewayTimeout converts the error to a gateway timeout error.
+func (e *AppError) AsGatewayTimeout() *AppError {
+	return e.setTypeAndStatus(ErrorTypeGatewayTimeout).WithCode(CodeGatewayTimeout)
+}
+
 // setTypeAndStatus sets the error type and updates the HTTP status.
 func (e *AppError) setTypeAndStatus(errorType ErrorType) *AppError {
 	if e == nil {
@@ -1,6 +1,7 @@
 package xerrs
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -205,6 +206,22 @@ func TestAsDatabaseConstraint(t *testing.T) {
 	assert.Equal(t, CodeDatabaseConstraint, result.Code)
 }
 
+func TestAsDatabaseDeadlock(t *testing.T) {
+	err := &AppError{}
+	result := err.AsDatabaseDeadlock()
+
+	assert.Equal(t, ErrorTypeInternal, result.Type)
+	assert.Equal(t, CodeDatabaseDeadlock, result.Code)
+}
+
+func TestAsDatabaseSerialization(t *testing.T) {
+	err := &AppError{}
+	result := err.AsDatabaseSerialization()
+
+	assert.Equal(t, ErrorTypeInternal, result.Type)
+	assert.Equal(t, CodeDatabaseSerialization, result.Code)
+}
+
 func TestAsExternalWithCode(t *testing.T) {
 	err := &AppError{}
 	result := err.AsExternalWithCode("EXTERNAL_CODE")
@@ -261,6 +278,36 @@ func TestAsTimeout(t *testing.T) {
 	assert.Equal(t, CodeInternalTimeout, result.Code)
 }
 
+func TestHTTPSemanticChaining(t *testing.T) {
+	tests := []struct {
+		name           string
+		convert        func(*AppError) *AppError
+		expectedType   ErrorType
+		expectedCode   string
+		expectedStatus int
+	}{
+		{"Method Not Allowed", (*AppError).AsMethodNotAllowed, ErrorTypeMethodNotAllowed, CodeMethodNotAllowed, http.StatusMethodNotAllowed},
+		{"Request Timeout", (*AppError).AsRequestTimeout, ErrorTypeRequestTimeout, CodeRequestTimeout, http.StatusRequestTimeout},
+		{"Gone", (*AppError).AsGone, ErrorTypeGone, CodeResourceGone, http.StatusGone},
+		{"Precondition Failed", (*AppError).AsPreconditionFailed, ErrorTypePreconditionFailed, CodePreconditionFailed, http.StatusPreconditionFailed},
+		{"Payload Too Large", (*AppError).AsPayloadTooLarge, ErrorTypePayloadTooLarge, CodePayloadTooLarge, http.StatusRequestEntityTooLarge},
+		{"Unsupported Media Type", (*AppError).AsUnsupportedMediaType, ErrorTypeUnsupportedMediaType, CodeUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"Unprocessable Entity", (*AppError).AsUnprocessableEntity, ErrorTypeUnprocessable, CodeUnprocessableEntity, http.StatusUnprocessableEntity},
+		{"Client Closed", (*AppError).AsClientClosed, ErrorTypeClientClosed, CodeClientClosedRequest, StatusClientClosedRequest},
+		{"Not Implemented", (*AppError).AsNotImplemented, ErrorTypeNotImplemented, CodeNotImplemented, http.StatusNotImplemented},
+		{"Gateway Timeout", (*AppError).AsGatewayTimeout, ErrorTypeGatewayTimeout, CodeGatewayTimeout, http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.convert(&AppError{})
+			assert.Equal(t, tt.expectedType, result.Type)
+			assert.Equal(t, tt.expectedCode, result.Code)
+			assert.Equal(t, tt.expectedStatus, result.GetHTTPStatus())
+		})
+	}
+}
+
 func TestSetTypeAndStatus_NilError(t *testing.T) {
 	var err *AppError
 	result := err.setTypeAndStatus(ErrorTypeValidation)
@@ -0,0 +1,267 @@
+package xerrs
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// DefaultMaxErrorBodyBytes is the default number of response body bytes read
+// when decoding an upstream error.
+const DefaultMaxErrorBodyBytes = 64 << 10
+
+// TransportOptions configures Transport and DecodeResponse.
+type TransportOptions struct {
+	// Service is the upstream name recorded with WithUpstream. Defaults to
+	// the request host.
+	Service string
+	// PassthroughNotFound maps upstream 404 responses to ErrorTypeNotFound
+	// instead of ErrorTypeExternal.
+	PassthroughNotFound bool
+	// PassthroughClientErrors maps upstream 4xx responses with StatusMapper
+	// instead of ErrorTypeExternal, so that an upstream 401, for example,
+	// becomes an authentication error.
+	PassthroughClientErrors bool
+	// MaxBodyBytes limits how much of an error body is read.
+	MaxBodyBytes int64
+	// StatusMapper maps the status of problem details bodies without a code
+	// to the type and code of the decoded cause, and the status of 4xx
+	// responses with PassthroughClientErrors. Defaults to DefaultStatusMapper.
+	StatusMapper *StatusMapper
+}
+
+// Transport is an http.RoundTripper that converts 4xx and 5xx responses and
+// transport failures into *AppError. Informational and redirect responses are
+// returned unchanged, so http.Client still follows redirects. Errors returned
+// through http.Client are wrapped in *url.Error; use AsAppError to retrieve them.
+type Transport struct {
+	base http.RoundTripper
+	opts TransportOptions
+}
+
+// NewTransport returns a Transport sending requests through base, or
+// http.DefaultTransport when base is nil. A nil opts uses the defaults.
+func NewTransport(base http.RoundTripper, opts *TransportOptions) *Transport {
+	t := &Transport{base: base}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if opts != nil {
+		t.opts = *opts
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, transportError(req, err, t.opts, time.Since(start))
+	}
+	if appErr := decodeResponse(resp, t.opts, time.Since(start)); appErr != nil {
+		return nil, appErr
+	}
+	return resp, nil
+}
+
+// DecodeResponse returns nil for responses with a status below 400. Otherwise
+// it reads and closes the body and returns an *AppError describing the
+// upstream failure:
+//
+//   - 5xx responses become ErrorTypeExternal errors, with CodeExternalTimeout
+//     for 504 and CodeExternalUnavailable for 503;
+//   - 429 responses become rate limit errors;
+//   - 404 responses become not found errors when PassthroughNotFound is set;
+//   - other 4xx responses are mapped with StatusMapper when
+//     PassthroughClientErrors is set, and become ErrorTypeExternal errors
+//     otherwise.
+//
+// By default, a 4xx response is a failure of this service rather than of its
+// own clients: it sent a request the upstream rejected, or its credentials
+// for the upstream were refused. Passing a 400 or 401 through would blame the
+// client of this service, so such responses map to 502 Bad Gateway.
+//
+// Retry-After headers set RetryAfter. Bodies in the AppError JSON encoding or
+// in the RFC 9457 problem details format are decoded into an *AppError kept as
+// the cause, so the upstream type and code remain available through Unwrap.
+// A nil opts uses the defaults.
+func DecodeResponse(resp *http.Response, opts *TransportOptions) error {
+	var o TransportOptions
+	if opts != nil {
+		o = *opts
+	}
+	if appErr := decodeResponse(resp, o, 0); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// upstreamBody holds the fields read from an AppError JSON or problem details body.
+type upstreamBody struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details"`
+	Title   string `json:"title"`
+	Detail  string `json:"detail"`
+}
+
+// decodeResponse implements DecodeResponse, recording latency in the upstream.
+func decodeResponse(resp *http.Response, opts TransportOptions, latency time.Duration) *AppError {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	limit := opts.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxErrorBodyBytes
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, limit))
+	_ = resp.Body.Close()
+
+	upstream := requestUpstream(resp.Request, opts, latency)
+	upstream.Status = resp.StatusCode
+	message := fmt.Sprintf("upstream %s returned %s", upstream.Service, statusLine(resp))
+
+	// The upstream error is kept as is, rather than merged by wrap, so that
+	// its type and code remain available on the cause.
+	errorType, code := responseType(resp.StatusCode, opts)
+	appErr := wrapAs(GetStackPolicy(), 1, upstreamCause(resp, body, opts), errorType, code, message)
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		appErr.WithRetryAfter(d)
+	}
+	return appErr.WithUpstream(upstream)
+}
+
+// responseType returns the type and code of the error describing an upstream
+// response with the given status, as documented on DecodeResponse.
+func responseType(status int, opts TransportOptions) (ErrorType, string) {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrorTypeRateLimit, CodeRateLimitExceeded
+	case status == http.StatusNotFound && opts.PassthroughNotFound:
+		return ErrorTypeNotFound, CodeResourceNotFound
+	case status == http.StatusServiceUnavailable:
+		return ErrorTypeExternal, CodeExternalUnavailable
+	case status == http.StatusGatewayTimeout:
+		return ErrorTypeExternal, CodeExternalTimeout
+	case status < http.StatusInternalServerError && opts.PassthroughClientErrors:
+		mapping := opts.statusMapper().Lookup(status)
+		return mapping.Type, mapping.Code
+	default:
+		return ErrorTypeExternal, CodeExternalError
+	}
+}
+
+// statusMapper returns the StatusMapper of the options, or DefaultStatusMapper.
+func (o TransportOptions) statusMapper() *StatusMapper {
+	if o.StatusMapper != nil {
+		return o.StatusMapper
+	}
+	return DefaultStatusMapper
+}
+
+// upstreamCause returns the error reported by an upstream response body.
+// Types that are not registered, such as the "about:blank" type of problem
+// details, are replaced by the one mapped from the status.
+func upstreamCause(resp *http.Response, body []byte, opts TransportOptions) error {
+	var decoded upstreamBody
+	if json.Unmarshal(body, &decoded) == nil {
+		policy := StackPolicy{Capture: StackCaptureNever}
+		switch {
+		case decoded.Code != "" && decoded.Message != "":
+			errorType := ErrorType(decoded.Type)
+			if _, ok := LookupErrorType(errorType); !ok {
+				errorType = opts.statusMapper().Lookup(resp.StatusCode).Type
+			}
+			return newAppError(policy, 0, errorType, decoded.Code, decoded.Message).
+				WithDetails(decoded.Details).
+				WithHTTPStatus(resp.StatusCode)
+		case isProblemJSON(resp.Header.Get("Content-Type")) || decoded.Title != "":
+			mapping := opts.statusMapper().Lookup(resp.StatusCode)
+			if decoded.Code != "" {
+				mapping.Code = decoded.Code
+			}
+			message := decoded.Title
+			if message == "" {
+				message = http.StatusText(resp.StatusCode)
+			}
+			return newAppError(policy, 0, mapping.Type, mapping.Code, message).
+				WithDetails(decoded.Detail).
+				WithHTTPStatus(resp.StatusCode)
+		}
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return stderrors.New(text)
+	}
+	return stderrors.New(statusLine(resp))
+}
+
+// transportError classifies a failed round trip: timeouts become
+// CodeExternalTimeout errors, cancellations keep their detected type, and
+// other failures become CodeExternalUnavailable errors.
+func transportError(req *http.Request, err error, opts TransportOptions, latency time.Duration) *AppError {
+	upstream := requestUpstream(req, opts, latency)
+	appErr := wrap(GetStackPolicy(), 1, err, fmt.Sprintf("call upstream %s", upstream.Service))
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		appErr.AsServiceTimeout()
+	default:
+		appErr.AsExternalServiceUnavailable()
+	}
+	return appErr.WithUpstream(upstream)
+}
+
+// requestUpstream returns the upstream identity of a request.
+func requestUpstream(req *http.Request, opts TransportOptions, latency time.Duration) Upstream {
+	u := Upstream{Service: opts.Service, Latency: latency}
+	if req != nil && req.URL != nil {
+		if u.Service == "" {
+			u.Service = req.URL.Host
+		}
+		u.Endpoint = req.Method + " " + req.URL.Path
+	}
+	return u
+}
+
+// statusLine returns the status of resp, such as "503 Service Unavailable".
+func statusLine(resp *http.Response) string {
+	if resp.Status != "" {
+		return resp.Status
+	}
+	return fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
+
+// isProblemJSON reports whether contentType is application/problem+json.
+func isProblemJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == MediaTypeProblemJSON
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		return d, d > 0
+	}
+	return 0, false
+}
@@ -0,0 +1,281 @@
+package xerrs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUpstreamServer(t *testing.T, status int, contentType, body string, header http.Header) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransport_StatusMapping(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		opts         TransportOptions
+		expectedType ErrorType
+		expectedCode string
+	}{
+		{"Bad Gateway", http.StatusBadGateway, TransportOptions{}, ErrorTypeExternal, CodeExternalError},
+		{"Internal Server Error", http.StatusInternalServerError, TransportOptions{}, ErrorTypeExternal, CodeExternalError},
+		{"Service Unavailable", http.StatusServiceUnavailable, TransportOptions{}, ErrorTypeExternal, CodeExternalUnavailable},
+		{"Gateway Timeout", http.StatusGatewayTimeout, TransportOptions{}, ErrorTypeExternal, CodeExternalTimeout},
+		{"Too Many Requests", http.StatusTooManyRequests, TransportOptions{}, ErrorTypeRateLimit, CodeRateLimitExceeded},
+		{"Not Found", http.StatusNotFound, TransportOptions{}, ErrorTypeExternal, CodeExternalError},
+		{"Not Found Passthrough", http.StatusNotFound, TransportOptions{PassthroughNotFound: true}, ErrorTypeNotFound, CodeResourceNotFound},
+		{"Bad Request", http.StatusBadRequest, TransportOptions{}, ErrorTypeExternal, CodeExternalError},
+		{"Bad Request Passthrough", http.StatusBadRequest, TransportOptions{PassthroughClientErrors: true}, ErrorTypeValidation, CodeValidationError},
+		{"Unauthorized Passthrough", http.StatusUnauthorized, TransportOptions{PassthroughClientErrors: true}, ErrorTypeAuthentication, CodeAuthRequired},
+		{"Conflict Passthrough", http.StatusConflict, TransportOptions{PassthroughClientErrors: true}, ErrorTypeConflict, CodeResourceExists},
+		{"Server Error Passthrough", http.StatusInternalServerError, TransportOptions{PassthroughClientErrors: true}, ErrorTypeExternal, CodeExternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newUpstreamServer(t, tt.status, "text/plain", "upstream said no", nil)
+			client := &http.Client{Transport: NewTransport(nil, &tt.opts)}
+
+			resp, err := client.Get(server.URL + "/v1/items")
+			require.Error(t, err)
+			assert.Nil(t, resp)
+
+			appErr, ok := AsAppError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedType, appErr.Type)
+			assert.Equal(t, tt.expectedCode, appErr.Code)
+			assert.Equal(t, "upstream said no", appErr.UnwrapAll().Error())
+
+			u, ok := appErr.Upstream()
+			require.True(t, ok)
+			assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), u.Service)
+			assert.Equal(t, "GET /v1/items", u.Endpoint)
+			assert.Equal(t, tt.status, u.Status)
+		})
+	}
+}
+
+func TestTransport_Success(t *testing.T) {
+	server := newUpstreamServer(t, http.StatusOK, "text/plain", "ok", nil)
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestTransport_FollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("moved"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	resp, err := client.Get(server.URL + "/old")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/new", resp.Request.URL.Path)
+}
+
+func TestTransport_NotModified(t *testing.T) {
+	server := newUpstreamServer(t, http.StatusNotModified, "", "", nil)
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestTransport_RetryAfter(t *testing.T) {
+	server := newUpstreamServer(t, http.StatusTooManyRequests, "", "", http.Header{"Retry-After": {"7"}})
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	_, err := client.Get(server.URL)
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, 7*time.Second, appErr.RetryAfter())
+	assert.True(t, appErr.Retryable())
+}
+
+func TestTransport_DecodesAppErrorBody(t *testing.T) {
+	body := `{"type":"NOT_FOUND","code":"USER_NOT_FOUND","message":"user 42 not found","details":"id=42"}`
+	server := newUpstreamServer(t, http.StatusNotFound, "application/json", body, nil)
+	client := &http.Client{Transport: NewTransport(nil, &TransportOptions{Service: "users", PassthroughNotFound: true})}
+
+	_, err := client.Get(server.URL + "/users/42")
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeNotFound, appErr.Type)
+	assert.Equal(t, CodeResourceNotFound, appErr.Code)
+	assert.Equal(t, "upstream users returned 404 Not Found", appErr.Message)
+	assert.Empty(t, appErr.Details)
+
+	cause, ok := AsAppError(appErr.Unwrap())
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeNotFound, cause.Type)
+	assert.Equal(t, "USER_NOT_FOUND", cause.Code)
+	assert.Equal(t, "user 42 not found", cause.Message)
+	assert.Equal(t, "id=42", cause.Details)
+}
+
+func TestTransport_DecodesProblemDetails(t *testing.T) {
+	body := `{"type":"https://example.com/probs/overloaded","title":"Service overloaded","status":503,"detail":"queue full"}`
+	server := newUpstreamServer(t, http.StatusServiceUnavailable, "application/problem+json", body, nil)
+	client := &http.Client{Transport: NewTransport(nil, &TransportOptions{Service: "search"})}
+
+	_, err := client.Get(server.URL)
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeExternalUnavailable, appErr.Code)
+
+	cause, ok := AsAppError(appErr.Unwrap())
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeUnavailable, cause.Type)
+	assert.Equal(t, "Service overloaded", cause.Message)
+	assert.Equal(t, "queue full", cause.Details)
+	assert.Equal(t, http.StatusServiceUnavailable, cause.HTTPStatus)
+}
+
+func TestTransport_UnregisteredBodyType(t *testing.T) {
+	body := `{"type":"about:blank","code":"QUEUE_FULL","message":"queue full"}`
+	server := newUpstreamServer(t, http.StatusServiceUnavailable, "application/problem+json", body, nil)
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	_, err := client.Get(server.URL)
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+
+	cause, ok := AsAppError(appErr.Unwrap())
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeUnavailable, cause.Type)
+	assert.Equal(t, "QUEUE_FULL", cause.Code)
+}
+
+func TestDecodeResponse_StackPolicy(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureServerErrors})
+
+	tests := []struct {
+		name      string
+		status    int
+		withStack bool
+	}{
+		{"Too Many Requests", http.StatusTooManyRequests, false},
+		{"Bad Gateway", http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newUpstreamServer(t, tt.status, "", "", nil)
+			resp, err := http.Get(server.URL)
+			require.NoError(t, err)
+
+			appErr, ok := AsAppError(DecodeResponse(resp, nil))
+			require.True(t, ok)
+			assert.Equal(t, tt.withStack, len(appErr.StackFrames()) > 0)
+		})
+	}
+}
+
+func TestTransport_TransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, &TransportOptions{Service: "slow"})}
+	t.Run("Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+		_, err := client.Do(req)
+		appErr, ok := AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeExternalTimeout, appErr.Code)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+		_, err := client.Do(req)
+		appErr, ok := AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeOperationCanceled, appErr.Code)
+	})
+
+	t.Run("Connection Refused", func(t *testing.T) {
+		_, err := client.Get(closedURL)
+		appErr, ok := AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, ErrorTypeExternal, appErr.Type)
+		assert.Equal(t, CodeExternalUnavailable, appErr.Code)
+		u, _ := appErr.Upstream()
+		assert.Equal(t, "slow", u.Service)
+	})
+}
+
+func TestDecodeResponse(t *testing.T) {
+	server := newUpstreamServer(t, http.StatusBadGateway, "", "", nil)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	decoded := DecodeResponse(resp, nil)
+	appErr, ok := AsAppError(decoded)
+	require.True(t, ok)
+	assert.Equal(t, CodeExternalError, appErr.Code)
+	assert.Equal(t, "502 Bad Gateway", appErr.UnwrapAll().Error())
+
+	ok200 := newUpstreamServer(t, http.StatusOK, "", "", nil)
+	resp, err = http.Get(ok200.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.NoError(t, DecodeResponse(resp, nil))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, d, float64(2*time.Second))
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("0")
+	assert.False(t, ok)
+}
@@ -0,0 +1,184 @@
+// Package connectx encodes and decodes xerrs errors in the Connect protocol
+// JSON error format.
+package connectx
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// ContentType is the content type of Connect error responses.
+const ContentType = "application/json"
+
+// Error is the Connect JSON error format.
+type Error struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message,omitempty"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail is a protobuf message attached to an Error.
+type ErrorDetail struct {
+	// Type is the fully qualified message name, such as google.rpc.ErrorInfo.
+	Type string `json:"type"`
+	// Value is the base64-encoded binary protobuf message.
+	Value string `json:"value"`
+	// Debug is an optional JSON rendering of the message, ignored by Decode.
+	Debug json.RawMessage `json:"debug,omitempty"`
+}
+
+// codeNames names the gRPC codes in the Connect protocol.
+var codeNames = xerrs.RPCCodeNames{
+	Renamed: map[uint32]string{
+		1: "canceled", // CANCELLED
+	},
+}
+
+// httpStatuses maps Connect codes to the HTTP statuses of unary responses.
+var httpStatuses = map[string]int{
+	"canceled":            xerrs.StatusClientClosedRequest,
+	"unknown":             http.StatusInternalServerError,
+	"invalid_argument":    http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusGatewayTimeout,
+	"not_found":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusBadRequest,
+	"aborted":             http.StatusConflict,
+	"out_of_range":        http.StatusBadRequest,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"data_loss":           http.StatusInternalServerError,
+	"unauthenticated":     http.StatusUnauthorized,
+}
+
+// Encode converts err to the Connect error format. The code comes from the
+// error type, as for gRPC; the error code, type and HTTP status are carried
+// in a google.rpc.ErrorInfo detail, Details in a google.rpc.DebugInfo detail
+// and RetryAfter in a google.rpc.RetryInfo detail. It returns nil for a nil err.
+func Encode(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	appErr := xerrs.ToAppError(err)
+	e := &Error{Code: codeNames.Name(appErr.GRPCCode()), Message: appErr.Message}
+	e.addDetail(&errdetails.ErrorInfo{
+		Reason: appErr.Code,
+		Domain: xerrs.ErrorInfoDomain,
+		Metadata: map[string]string{
+			xerrs.ErrorInfoMetadataType:       string(appErr.Type),
+			xerrs.ErrorInfoMetadataHTTPStatus: strconv.Itoa(appErr.GetHTTPStatus()),
+		},
+	})
+	if appErr.Details != "" {
+		e.addDetail(&errdetails.DebugInfo{Detail: appErr.Details})
+	}
+	if d := appErr.RetryAfter(); d > 0 {
+		e.addDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	return e
+}
+
+// addDetail appends msg to the details of e.
+func (e *Error) addDetail(msg proto.Message) {
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		return
+	}
+	e.Details = append(e.Details, ErrorDetail{
+		Type:  string(proto.MessageName(msg)),
+		Value: base64.RawStdEncoding.EncodeToString(value),
+	})
+}
+
+// Decode converts a Connect error to an *AppError. The type, code and HTTP
+// status are read from the ErrorInfo detail added by Encode; errors from
+// other servers are classified by their Connect code. It returns nil for a
+// nil e.
+func Decode(e *Error) *xerrs.AppError {
+	if e == nil {
+		return nil
+	}
+	mapping := xerrs.GRPCCodeMapping(codeNames.Code(e.Code))
+	httpStatus := 0
+	var details string
+	var retryAfter *durationpb.Duration
+	for _, detail := range e.Details {
+		value, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(detail.Value, "="))
+		if err != nil {
+			continue
+		}
+		switch detail.Type {
+		case string(proto.MessageName(&errdetails.ErrorInfo{})):
+			var info errdetails.ErrorInfo
+			if proto.Unmarshal(value, &info) != nil || info.GetDomain() != xerrs.ErrorInfoDomain {
+				continue
+			}
+			if t := info.GetMetadata()[xerrs.ErrorInfoMetadataType]; t != "" {
+				mapping.Type = xerrs.ErrorType(t)
+			}
+			if info.GetReason() != "" {
+				mapping.Code = info.GetReason()
+			}
+			httpStatus, _ = strconv.Atoi(info.GetMetadata()[xerrs.ErrorInfoMetadataHTTPStatus])
+		case string(proto.MessageName(&errdetails.DebugInfo{})):
+			var info errdetails.DebugInfo
+			if proto.Unmarshal(value, &info) == nil {
+				details = info.GetDetail()
+			}
+		case string(proto.MessageName(&errdetails.RetryInfo{})):
+			var info errdetails.RetryInfo
+			if proto.Unmarshal(value, &info) == nil {
+				retryAfter = info.GetRetryDelay()
+			}
+		}
+	}
+	// A zero status keeps the default status of the type.
+	appErr := xerrs.NewAppError(mapping.Type, mapping.Code, e.Message).
+		WithHTTPStatus(httpStatus).
+		WithDetails(details)
+	if retryAfter != nil {
+		appErr.WithRetryAfter(retryAfter.AsDuration())
+	}
+	return appErr
+}
+
+// Unmarshal decodes a Connect JSON error body into an *AppError.
+func Unmarshal(data []byte) (*xerrs.AppError, error) {
+	var e Error
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, xerrs.Wrap(err, "decode connect error").AsInvalidFormat()
+	}
+	return Decode(&e), nil
+}
+
+// WriteError writes err as a Connect unary error response, with the HTTP
+// status of its Connect code.
+func WriteError(w http.ResponseWriter, err error) {
+	e := Encode(err)
+	if e == nil {
+		e = Encode(xerrs.New(http.StatusText(http.StatusInternalServerError)))
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(HTTPStatus(e.Code))
+	_ = json.NewEncoder(w).Encode(e)
+}
+
+// HTTPStatus returns the HTTP status of unary responses for a Connect code.
+func HTTPStatus(code string) int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
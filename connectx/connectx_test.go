@@ -0,0 +1,140 @@
+package connectx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestRoundTripAllTypes(t *testing.T) {
+	for _, errorType := range xerrs.RegisteredErrorTypes() {
+		t.Run(string(errorType), func(t *testing.T) {
+			original := xerrs.NewAppError(errorType, "SOME_CODE", "something failed").
+				WithDetails("more context").
+				WithRetryAfter(2 * time.Second)
+
+			data, err := json.Marshal(Encode(original))
+			require.NoError(t, err)
+			decoded, err := Unmarshal(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, errorType, decoded.Type)
+			assert.Equal(t, "SOME_CODE", decoded.Code)
+			assert.Equal(t, "something failed", decoded.Message)
+			assert.Equal(t, "more context", decoded.Details)
+			assert.Equal(t, original.GetHTTPStatus(), decoded.GetHTTPStatus())
+			assert.Equal(t, 2*time.Second, decoded.RetryAfter())
+			assert.Equal(t, codeNames.Name(original.GRPCCode()), Encode(decoded).Code)
+		})
+	}
+}
+
+func TestEncodeCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"validation", xerrs.NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput, "bad"), "invalid_argument"},
+		{"not found", xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "missing"), "not_found"},
+		{"resource exists", xerrs.NewAppError(xerrs.ErrorTypeConflict, xerrs.CodeResourceExists, "dup"), "already_exists"},
+		{"other conflict", xerrs.NewAppError(xerrs.ErrorTypeConflict, "VERSION_MISMATCH", "stale"), "aborted"},
+		{"rate limit", xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow"), "resource_exhausted"},
+		{"unavailable", xerrs.NewAppError(xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable, "down"), "unavailable"},
+		{"plain error", errors.New("duplicate key value"), "already_exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Encode(tt.err).Code)
+		})
+	}
+	assert.Nil(t, Encode(nil))
+}
+
+func TestDecodeForeign(t *testing.T) {
+	tests := []struct {
+		body     string
+		wantType xerrs.ErrorType
+		wantCode string
+	}{
+		{`{"code":"invalid_argument","message":"bad"}`, xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput},
+		{`{"code":"unauthenticated","message":"bad"}`, xerrs.ErrorTypeAuthentication, xerrs.CodeAuthRequired},
+		{`{"code":"not_found","message":"bad"}`, xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound},
+		{`{"code":"deadline_exceeded","message":"bad"}`, xerrs.ErrorTypeGatewayTimeout, xerrs.CodeGatewayTimeout},
+		{`{"code":"unavailable","message":"bad"}`, xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable},
+		{`{"code":"bogus","message":"bad"}`, xerrs.ErrorTypeInternal, xerrs.CodeInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			appErr, err := Unmarshal([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, appErr.Type)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, "bad", appErr.Message)
+			assert.Equal(t, tt.wantType.DefaultHTTPStatus(), appErr.GetHTTPStatus())
+		})
+	}
+
+	_, err := Unmarshal([]byte("not json"))
+	appErr, ok := xerrs.AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, xerrs.CodeInvalidFormat, appErr.Code)
+	assert.Nil(t, Decode(nil))
+}
+
+func TestEncodeDetailFormat(t *testing.T) {
+	e := Encode(xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "missing").WithDetails("user 42"))
+	require.Len(t, e.Details, 2)
+	assert.Equal(t, "google.rpc.ErrorInfo", e.Details[0].Type)
+	assert.Equal(t, "google.rpc.DebugInfo", e.Details[1].Type)
+	assert.NotContains(t, e.Details[0].Value, "=")
+
+	// Padded values, as sent by some implementations, are accepted.
+	e.Details[1].Value += "="
+	assert.Equal(t, "user 42", Decode(e).Details)
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow down"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	var body Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "resource_exhausted", body.Code)
+	assert.Equal(t, "slow down", body.Message)
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, xerrs.StatusClientClosedRequest, HTTPStatus("canceled"))
+	assert.Equal(t, http.StatusBadRequest, HTTPStatus("failed_precondition"))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus("bogus"))
+}
+
+func TestCodeNames(t *testing.T) {
+	tests := []struct {
+		code uint32
+		name string
+	}{
+		{1, "canceled"},
+		{3, "invalid_argument"},
+		{15, "data_loss"},
+		{16, "unauthenticated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, codeNames.Name(tt.code))
+			assert.Equal(t, tt.code, codeNames.Code(tt.name))
+		})
+	}
+}
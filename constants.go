@@ -28,17 +28,29 @@ const (
 	CodeResourceNotFound = "RESOURCE_NOT_FOUND"
 	CodeResourceExists   = "RESOURCE_EXISTS"
 
+	// Request error codes (405, 408, 410, 412, 413, 415, 422, 499)
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeRequestTimeout       = "REQUEST_TIMEOUT"
+	CodeResourceGone         = "RESOURCE_GONE"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessableEntity  = "UNPROCESSABLE_ENTITY"
+	CodeClientClosedRequest  = "CLIENT_CLOSED_REQUEST"
+
 	// Rate limit error codes (429)
 	CodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
 
 	// Internal system error codes (500)
-	CodeInternalError      = "INTERNAL_ERROR"
-	CodeDatabaseError      = "DATABASE_ERROR"
-	CodeDatabaseConnection = "DATABASE_CONNECTION"
-	CodeDatabaseConstraint = "DATABASE_CONSTRAINT"
-	CodeInternalTimeout    = "INTERNAL_TIMEOUT"
-	CodeConfigurationError = "CONFIGURATION_ERROR"
-	CodeOperationCanceled  = "OPERATION_CANCELED"
+	CodeInternalError         = "INTERNAL_ERROR"
+	CodeDatabaseError         = "DATABASE_ERROR"
+	CodeDatabaseConnection    = "DATABASE_CONNECTION"
+	CodeDatabaseConstraint    = "DATABASE_CONSTRAINT"
+	CodeInternalTimeout       = "INTERNAL_TIMEOUT"
+	CodeConfigurationError    = "CONFIGURATION_ERROR"
+	CodeOperationCanceled     = "OPERATION_CANCELED"
+	CodeDatabaseDeadlock      = "DATABASE_DEADLOCK"
+	CodeDatabaseSerialization = "DATABASE_SERIALIZATION_FAILURE"
 
 	// Context and middleware error codes (500)
 	CodeInvalidUserContext    = "INVALID_USER_CONTEXT"
@@ -54,6 +66,10 @@ const (
 
 	// Service unavailable error codes (503)
 	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+
+	// Not implemented and gateway timeout error codes (501, 504)
+	CodeNotImplemented = "NOT_IMPLEMENTED"
+	CodeGatewayTimeout = "GATEWAY_TIMEOUT"
 )
 
 // ErrorType defines the category of application errors for HTTP status mapping.
@@ -68,45 +84,38 @@ const (
 	ErrorTypeConflict       ErrorType = "CONFLICT"       // 409 Conflict
 	ErrorTypeRateLimit      ErrorType = "RATE_LIMIT"     // 429 Too Many Requests
 
+	ErrorTypeMethodNotAllowed     ErrorType = "METHOD_NOT_ALLOWED"     // 405 Method Not Allowed
+	ErrorTypeRequestTimeout       ErrorType = "REQUEST_TIMEOUT"        // 408 Request Timeout
+	ErrorTypeGone                 ErrorType = "GONE"                   // 410 Gone
+	ErrorTypePreconditionFailed   ErrorType = "PRECONDITION_FAILED"    // 412 Precondition Failed
+	ErrorTypePayloadTooLarge      ErrorType = "PAYLOAD_TOO_LARGE"      // 413 Payload Too Large
+	ErrorTypeUnsupportedMediaType ErrorType = "UNSUPPORTED_MEDIA_TYPE" // 415 Unsupported Media Type
+	ErrorTypeUnprocessable        ErrorType = "UNPROCESSABLE_ENTITY"   // 422 Unprocessable Entity
+	ErrorTypeClientClosed         ErrorType = "CLIENT_CLOSED"          // 499 Client Closed Request
+
 	// Server-side Errors (5xx)
-	ErrorTypeInternal    ErrorType = "INTERNAL"    // 500 Internal Server Error
-	ErrorTypeExternal    ErrorType = "EXTERNAL"    // 502 Bad Gateway
-	ErrorTypeUnavailable ErrorType = "UNAVAILABLE" // 503 Service Unavailable
+	ErrorTypeInternal       ErrorType = "INTERNAL"        // 500 Internal Server Error
+	ErrorTypeNotImplemented ErrorType = "NOT_IMPLEMENTED" // 501 Not Implemented
+	ErrorTypeExternal       ErrorType = "EXTERNAL"        // 502 Bad Gateway
+	ErrorTypeUnavailable    ErrorType = "UNAVAILABLE"     // 503 Service Unavailable
+	ErrorTypeGatewayTimeout ErrorType = "GATEWAY_TIMEOUT" // 504 Gateway Timeout
 )
 
 // Default HTTP status codes for error types.
 const (
 	StatusInternalServerError = http.StatusInternalServerError
+	// StatusClientClosedRequest is the non-standard status, introduced by
+	// nginx, for requests abandoned by the client.
+	StatusClientClosedRequest = 499
 )
 
-// DefaultHTTPStatus returns the default HTTP status code for each error type.
+// DefaultHTTPStatus returns the default HTTP status code for each error type,
+// as registered with RegisterErrorType. Unknown types map to 500.
 func (et ErrorType) DefaultHTTPStatus() int {
-	switch et {
-	// Client-side errors (4xx)
-	case ErrorTypeValidation:
-		return http.StatusBadRequest
-	case ErrorTypeAuthentication:
-		return http.StatusUnauthorized
-	case ErrorTypeAuthorization:
-		return http.StatusForbidden
-	case ErrorTypeNotFound:
-		return http.StatusNotFound
-	case ErrorTypeConflict:
-		return http.StatusConflict
-	case ErrorTypeRateLimit:
-		return http.StatusTooManyRequests
-
-	// Server-side errors (5xx)
-	case ErrorTypeInternal:
-		return http.StatusInternalServerError
-	case ErrorTypeExternal:
-		return http.StatusBadGateway
-	case ErrorTypeUnavailable:
-		return http.StatusServiceUnavailable
-
-	default:
-		return http.StatusInternalServerError
+	if opts, ok := LookupErrorType(et); ok && opts.HTTPStatus != 0 {
+		return opts.HTTPStatus
 	}
+	return http.StatusInternalServerError
 }
 
 // Error messages for consistent error reporting
@@ -22,6 +22,16 @@ func TestDefaultHTTPStatus(t *testing.T) {
 		{"Internal Error", ErrorTypeInternal, http.StatusInternalServerError},
 		{"External Error", ErrorTypeExternal, http.StatusBadGateway},
 		{"Unavailable Error", ErrorTypeUnavailable, http.StatusServiceUnavailable},
+		{"Method Not Allowed Error", ErrorTypeMethodNotAllowed, http.StatusMethodNotAllowed},
+		{"Request Timeout Error", ErrorTypeRequestTimeout, http.StatusRequestTimeout},
+		{"Gone Error", ErrorTypeGone, http.StatusGone},
+		{"Precondition Failed Error", ErrorTypePreconditionFailed, http.StatusPreconditionFailed},
+		{"Payload Too Large Error", ErrorTypePayloadTooLarge, http.StatusRequestEntityTooLarge},
+		{"Unsupported Media Type Error", ErrorTypeUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"Unprocessable Entity Error", ErrorTypeUnprocessable, http.StatusUnprocessableEntity},
+		{"Client Closed Error", ErrorTypeClientClosed, StatusClientClosedRequest},
+		{"Not Implemented Error", ErrorTypeNotImplemented, http.StatusNotImplemented},
+		{"Gateway Timeout Error", ErrorTypeGatewayTimeout, http.StatusGatewayTimeout},
 		{"Unknown Error Type", ErrorType("UNKNOWN"), http.StatusInternalServerError},
 	}
 
@@ -0,0 +1,17 @@
+package xerrs
+
+import "sync/atomic"
+
+// debugMode holds the global debug flag.
+var debugMode atomic.Bool
+
+// SetDebugMode enables or disables debug mode. In debug mode, encodings meant
+// for clients include internal details such as upstream attribution.
+func SetDebugMode(enabled bool) {
+	debugMode.Store(enabled)
+}
+
+// DebugMode reports whether debug mode is enabled.
+func DebugMode() bool {
+	return debugMode.Load()
+}
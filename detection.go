@@ -3,12 +3,23 @@ package xerrs
 import (
 	"context"
 	"database/sql"
+	stderrors "errors"
+	"net/http"
 	"strings"
 
 	"github.com/cockroachdb/errors"
 	"gorm.io/gorm"
 )
 
+// Classify returns the type and code of err: those of the first AppError in
+// the chain, or the ones Wrap would auto-detect for other errors.
+func Classify(err error) (ErrorType, string) {
+	if err == nil {
+		return "", ""
+	}
+	return detectErrorTypeAndCode(err)
+}
+
 // detectErrorTypeAndCode analyzes an error and returns appropriate ErrorType and Code.
 func detectErrorTypeAndCode(err error) (ErrorType, string) {
 	// Check if it's already an AppError
@@ -24,6 +35,12 @@ func detectErrorTypeAndCode(err error) (ErrorType, string) {
 	case errors.Is(err, context.Canceled):
 		return ErrorTypeInternal, CodeOperationCanceled
 
+	// HTTP request errors
+	case isMaxBytesError(err):
+		return ErrorTypePayloadTooLarge, CodePayloadTooLarge
+	case errors.Is(err, stderrors.ErrUnsupported):
+		return ErrorTypeNotImplemented, CodeNotImplemented
+
 	// Database-related errors (GORM)
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		return ErrorTypeNotFound, CodeResourceNotFound
@@ -71,6 +88,26 @@ var errorPatterns = []errorPattern{
 		errorType: ErrorTypeValidation,
 		code:      CodeInvalidFormat,
 	},
+	{
+		patterns:  []string{"request body too large", "request entity too large", "payload too large"},
+		errorType: ErrorTypePayloadTooLarge,
+		code:      CodePayloadTooLarge,
+	},
+	{
+		patterns:  []string{"unsupported media type", "unsupported content type"},
+		errorType: ErrorTypeUnsupportedMediaType,
+		code:      CodeUnsupportedMediaType,
+	},
+	{
+		patterns:  []string{"method not allowed"},
+		errorType: ErrorTypeMethodNotAllowed,
+		code:      CodeMethodNotAllowed,
+	},
+	{
+		patterns:  []string{"precondition failed", "etag mismatch"},
+		errorType: ErrorTypePreconditionFailed,
+		code:      CodePreconditionFailed,
+	},
 	{
 		patterns:  []string{"required", "missing"},
 		errorType: ErrorTypeValidation,
@@ -82,6 +119,18 @@ var errorPatterns = []errorPattern{
 		code:      CodeInvalidRange,
 	},
 
+	// Transient database errors (retryable)
+	{
+		patterns:  []string{"deadlock detected", "deadlock found", "sqlstate 40p01"},
+		errorType: ErrorTypeInternal,
+		code:      CodeDatabaseDeadlock,
+	},
+	{
+		patterns:  []string{"could not serialize access", "serialization failure", "sqlstate 40001"},
+		errorType: ErrorTypeInternal,
+		code:      CodeDatabaseSerialization,
+	},
+
 	// Database constraint errors (second priority - most common)
 	{
 		patterns:  []string{"duplicate key", "unique constraint", "already exists"},
@@ -135,6 +184,13 @@ var errorPatterns = []errorPattern{
 		code:      CodeRateLimitExceeded,
 	},
 
+	// Unimplemented functionality
+	{
+		patterns:  []string{"not implemented", "unimplemented"},
+		errorType: ErrorTypeNotImplemented,
+		code:      CodeNotImplemented,
+	},
+
 	// Network errors (lower priority - more generic)
 	{
 		patterns:  []string{"connection refused", "connection reset", "no such host", "network is unreachable"},
@@ -183,6 +239,12 @@ func detectFromErrorMessage(errMsg string) (ErrorType, string) {
 	return ErrorTypeInternal, CodeInternalError
 }
 
+// isMaxBytesError reports whether err comes from http.MaxBytesReader.
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
 // IsRecordNotFound checks if an error indicates that a record was not found.
 func IsRecordNotFound(err error) bool {
 	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,6 +22,8 @@ func TestDetectErrorTypeAndCode(t *testing.T) {
 		{"AppError", &AppError{Type: ErrorTypeValidation, Code: CodeInvalidInput}, ErrorTypeValidation, CodeInvalidInput},
 		{"Context Deadline Exceeded", context.DeadlineExceeded, ErrorTypeInternal, CodeInternalTimeout},
 		{"Context Canceled", context.Canceled, ErrorTypeInternal, CodeOperationCanceled},
+		{"Max Bytes Error", &http.MaxBytesError{Limit: 10}, ErrorTypePayloadTooLarge, CodePayloadTooLarge},
+		{"Unsupported", fmt.Errorf("export: %w", errors.ErrUnsupported), ErrorTypeNotImplemented, CodeNotImplemented},
 		{"GORM Record Not Found", gorm.ErrRecordNotFound, ErrorTypeNotFound, CodeResourceNotFound},
 		{"GORM Invalid Transaction", gorm.ErrInvalidTransaction, ErrorTypeInternal, CodeDatabaseError},
 		{"GORM Missing Where Clause", gorm.ErrMissingWhereClause, ErrorTypeValidation, CodeInvalidInput},
@@ -71,6 +75,13 @@ func TestDetectFromErrorMessage(t *testing.T) {
 		{"Authentication Error - Token Expired", "token expired", ErrorTypeAuthentication, CodeTokenExpired},
 		{"Authorization Error - Access Denied", "access denied", ErrorTypeAuthorization, CodeAccessDenied},
 		{"Rate Limit Error", "rate limit exceeded", ErrorTypeRateLimit, CodeRateLimitExceeded},
+		{"Database Deadlock", "ERROR: deadlock detected (SQLSTATE 40P01)", ErrorTypeInternal, CodeDatabaseDeadlock},
+		{"Database Serialization", "could not serialize access due to concurrent update", ErrorTypeInternal, CodeDatabaseSerialization},
+		{"Payload Too Large", "http: request body too large", ErrorTypePayloadTooLarge, CodePayloadTooLarge},
+		{"Unsupported Media Type", "unsupported media type: text/xml", ErrorTypeUnsupportedMediaType, CodeUnsupportedMediaType},
+		{"Method Not Allowed", "method not allowed", ErrorTypeMethodNotAllowed, CodeMethodNotAllowed},
+		{"Precondition Failed", "precondition failed: etag mismatch", ErrorTypePreconditionFailed, CodePreconditionFailed},
+		{"Not Implemented", "export not implemented", ErrorTypeNotImplemented, CodeNotImplemented},
 		{"Default Case", "unknown error", ErrorTypeInternal, CodeInternalError},
 	}
 
@@ -167,3 +178,17 @@ func TestIsRecordNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestClassify(t *testing.T) {
+	errorType, code := Classify(New("x").AsServiceTimeout())
+	assert.Equal(t, ErrorTypeExternal, errorType)
+	assert.Equal(t, CodeExternalTimeout, code)
+
+	errorType, code = Classify(sql.ErrNoRows)
+	assert.Equal(t, ErrorTypeNotFound, errorType)
+	assert.Equal(t, CodeResourceNotFound, code)
+
+	errorType, code = Classify(nil)
+	assert.Empty(t, errorType)
+	assert.Empty(t, code)
+}
@@ -0,0 +1,115 @@
+package xerrs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors/errbase"
+	"github.com/cockroachdb/errors/errorspb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// encodedAppError is the payload of an AppError encoded with
+// errors.EncodeError. The cause chain is encoded by the errors library.
+type encodedAppError struct {
+	Type        ErrorType        `json:"type"`
+	Code        string           `json:"code"`
+	Message     string           `json:"message"`
+	Details     string           `json:"details,omitempty"`
+	HTTPStatus  int              `json:"http_status,omitempty"`
+	Fingerprint string           `json:"fingerprint,omitempty"`
+	Fields      []FieldError     `json:"fields,omitempty"`
+	Retryable   *bool            `json:"retryable,omitempty"`
+	RetryAfter  time.Duration    `json:"retry_after,omitempty"`
+	Attempts    int              `json:"attempts,omitempty"`
+	Upstream    *encodedUpstream `json:"upstream,omitempty"`
+}
+
+// encodedUpstream is the payload of the upstream of an encoded AppError.
+type encodedUpstream struct {
+	Service  string        `json:"service"`
+	Endpoint string        `json:"endpoint,omitempty"`
+	Status   int           `json:"status,omitempty"`
+	Latency  time.Duration `json:"latency,omitempty"`
+}
+
+// AppError is registered with the cockroachdb/errors encoding, so that
+// errors.DecodeError returns an *AppError for an encoded AppError, with its
+// cause chain intact. The type and code are reported as safe details.
+func init() {
+	typeKey := errbase.GetTypeKey(&AppError{})
+	errbase.RegisterWrapperEncoderWithMessageType(typeKey, encodeAppErrorWrapper)
+	errbase.RegisterWrapperDecoder(typeKey, decodeAppErrorWrapper)
+	errbase.RegisterLeafEncoder(typeKey, encodeAppErrorLeaf)
+	errbase.RegisterLeafDecoder(typeKey, decodeAppErrorLeaf)
+}
+
+func encodeAppErrorWrapper(_ context.Context, err error) (string, []string, proto.Message, errbase.MessageType) {
+	msg, safeDetails, payload := encodeAppError(err)
+	// Error() does not include the cause, so the message is complete.
+	return msg, safeDetails, payload, errbase.FullMessage
+}
+
+func decodeAppErrorWrapper(_ context.Context, cause error, msg string, _ []string, payload proto.Message) error {
+	appErr := decodeAppError(msg, payload)
+	appErr.cause = cause
+	return appErr
+}
+
+func encodeAppErrorLeaf(_ context.Context, err error) (string, []string, proto.Message) {
+	return encodeAppError(err)
+}
+
+func decodeAppErrorLeaf(_ context.Context, msg string, _ []string, payload proto.Message) error {
+	return decodeAppError(msg, payload)
+}
+
+// encodeAppError returns the message, safe details and payload of an encoded
+// AppError. The payload is the JSON encoding of its fields.
+func encodeAppError(err error) (string, []string, proto.Message) {
+	e := err.(*AppError)
+	var upstream *encodedUpstream
+	if u := e.upstream; u != nil {
+		upstream = &encodedUpstream{Service: u.Service, Endpoint: u.Endpoint, Status: u.Status, Latency: u.Latency}
+	}
+	data, marshalErr := json.Marshal(encodedAppError{
+		Type:        e.Type,
+		Code:        e.Code,
+		Message:     e.Message,
+		Details:     e.Details,
+		HTTPStatus:  e.HTTPStatus,
+		Fingerprint: e.Fingerprint(),
+		Fields:      e.fields,
+		Retryable:   e.retryable,
+		RetryAfter:  e.retryAfter,
+		Attempts:    e.attempts,
+		Upstream:    upstream,
+	})
+	var payload proto.Message
+	if marshalErr == nil {
+		payload = &errorspb.StringPayload{Msg: string(data)}
+	}
+	return e.Error(), []string{string(e.Type), e.Code}, payload
+}
+
+// decodeAppError rebuilds an AppError from its encoded payload. Without a
+// usable payload the error is an internal error with the encoded message.
+// The cause is a plain error with the message, replaced by the decoded cause
+// for wrappers.
+func decodeAppError(msg string, payload proto.Message) *AppError {
+	var in encodedAppError
+	p, ok := payload.(*errorspb.StringPayload)
+	if !ok || json.Unmarshal([]byte(p.Msg), &in) != nil {
+		return decodedAppError(ErrorTypeInternal, CodeInternalError, msg, "", 0, nil)
+	}
+	appErr := decodedAppError(in.Type, in.Code, in.Message, in.Details, in.HTTPStatus, in.Fields)
+	appErr.decodedFingerprint = in.Fingerprint
+	appErr.retryable = in.Retryable
+	appErr.retryAfter = in.RetryAfter
+	appErr.attempts = in.Attempts
+	if u := in.Upstream; u != nil {
+		appErr.upstream = &Upstream{Service: u.Service, Endpoint: u.Endpoint, Status: u.Status, Latency: u.Latency}
+	}
+	return appErr
+}
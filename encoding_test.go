@@ -0,0 +1,117 @@
+package xerrs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/errorspb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transmit encodes err, sends it through its protobuf wire form and decodes
+// it, as a remote service would.
+func transmit(t *testing.T, err error) error {
+	t.Helper()
+	ctx := context.Background()
+	enc := errors.EncodeError(ctx, err)
+	data, marshalErr := enc.Marshal()
+	require.NoError(t, marshalErr)
+
+	var received errorspb.EncodedError
+	require.NoError(t, received.Unmarshal(data))
+	return errors.DecodeError(ctx, received)
+}
+
+func TestEncodeError_RoundTrip(t *testing.T) {
+	original := Wrap(sql.ErrNoRows, "load user").
+		WithDetails("id=42").
+		WithHTTPStatus(http.StatusGone).
+		WithFieldError("id", CodeResourceNotFound, "unknown user").
+		WithRetryAfter(2 * time.Second).
+		WithRetryable(true)
+
+	decoded := transmit(t, original)
+
+	appErr, ok := decoded.(*AppError)
+	require.True(t, ok, "decoded %T", decoded)
+	assert.Equal(t, ErrorTypeNotFound, appErr.Type)
+	assert.Equal(t, original.Code, appErr.Code)
+	assert.Equal(t, "load user", appErr.Message)
+	assert.Equal(t, "id=42", appErr.Details)
+	assert.Equal(t, http.StatusGone, appErr.GetHTTPStatus())
+	assert.Equal(t, original.FieldErrors(), appErr.FieldErrors())
+	assert.Equal(t, 2*time.Second, appErr.RetryAfter())
+	assert.True(t, appErr.Retryable())
+	assert.Equal(t, original.Fingerprint(), appErr.Fingerprint())
+	assert.Equal(t, original.Error(), appErr.Error())
+	assert.Equal(t, original.Cause().Error(), appErr.Cause().Error())
+	assert.True(t, errors.Is(appErr, sql.ErrNoRows))
+	assert.True(t, errors.Is(appErr, original))
+}
+
+func TestEncodeError_Upstream(t *testing.T) {
+	upstream := Upstream{Service: "payments", Endpoint: "POST /v1/charges", Status: http.StatusServiceUnavailable, Latency: 1200 * time.Millisecond}
+	original := New("charge failed").AsExternalServiceUnavailable().WithUpstream(upstream)
+
+	appErr, ok := transmit(t, original).(*AppError)
+	require.True(t, ok)
+	got, ok := appErr.Upstream()
+	require.True(t, ok)
+	assert.Equal(t, upstream, got)
+
+	appErr, ok = transmit(t, New("boom")).(*AppError)
+	require.True(t, ok)
+	_, ok = appErr.Upstream()
+	assert.False(t, ok)
+}
+
+func TestEncodeError_NestedAppError(t *testing.T) {
+	inner := NewAppError(ErrorTypeUnavailable, CodeServiceUnavailable, "payments down").
+		WithDetails("status 503")
+	outer := NewAppError(ErrorTypeExternal, CodeExternalError, "charge failed").
+		WithCause(fmt.Errorf("call payments: %w", inner))
+
+	decoded := transmit(t, fmt.Errorf("checkout: %w", outer))
+	assert.Equal(t, fmt.Sprintf("checkout: %s", outer), decoded.Error())
+
+	appErr, ok := AsAppError(decoded)
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeExternal, appErr.Type)
+	assert.Equal(t, "charge failed", appErr.Message)
+
+	nested, ok := AsAppError(appErr.Cause())
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeUnavailable, nested.Type)
+	assert.Equal(t, CodeServiceUnavailable, nested.Code)
+	assert.Equal(t, "status 503", nested.Details)
+	assert.True(t, errors.Is(decoded, inner))
+}
+
+func TestEncodeError_Leaf(t *testing.T) {
+	original := &AppError{
+		Type:       ErrorTypeValidation,
+		Code:       CodeInvalidInput,
+		Message:    "bad input",
+		HTTPStatus: http.StatusBadRequest,
+	}
+
+	appErr, ok := transmit(t, original).(*AppError)
+	require.True(t, ok)
+	assert.Equal(t, original.Error(), appErr.Error())
+	assert.Equal(t, http.StatusBadRequest, appErr.GetHTTPStatus())
+}
+
+func TestEncodeError_SafeDetails(t *testing.T) {
+	err := NewAppError(ErrorTypeNotFound, CodeResourceNotFound, "user 42 not found")
+
+	enc := errors.EncodeError(context.Background(), err)
+	wrapper := enc.GetWrapper()
+	require.NotNil(t, wrapper)
+	assert.Equal(t, []string{string(ErrorTypeNotFound), CodeResourceNotFound}, wrapper.Details.ReportablePayload)
+}
@@ -2,7 +2,10 @@ package xerrs
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/cockroachdb/errors"
 )
@@ -10,16 +13,48 @@ import (
 // AppError represents a structured application error with HTTP mapping capabilities
 // and enhanced error details using cockroachdb/errors.
 type AppError struct {
-	Type       ErrorType `json:"type"`
-	Code       string    `json:"code"`
-	Message    string    `json:"message"`
-	Details    string    `json:"details,omitempty"`
-	HTTPStatus int       `json:"http_status,omitempty"`
-	cause      error     `json:"-"`
+	Type        ErrorType `json:"type"`
+	Code        string    `json:"code"`
+	Message     string    `json:"message"`
+	Details     string    `json:"details,omitempty"`
+	HTTPStatus  int       `json:"http_status,omitempty"`
+	cause       error     `json:"-"`
+	fingerprint []string
+	retryable   *bool
+	retryAfter  time.Duration
+	attempts    int
+	upstream    *Upstream
+	fields      []FieldError
+	// decodedFingerprint and decodedFrames are restored by UnmarshalJSON.
+	decodedFingerprint string
+	decodedFrames      []Frame
+	// site is the creation site, and cachedFingerprint the last computed
+	// fingerprint, for Fingerprint.
+	site              errorSite
+	cachedFingerprint atomic.Value
+	// pending holds the stacks recorded under StackCaptureServerErrors,
+	// innermost first, reported only for server error types.
+	pending [][]uintptr
 }
 
 // NewAppError creates a new AppError with specified type, code, and message.
 func NewAppError(errorType ErrorType, code, message string) *AppError {
+	return newAppError(GetStackPolicy(), 1, errorType, code, message)
+}
+
+// New creates a new AppError with a default internal error type and message.
+func New(message string) *AppError {
+	return newAppError(GetStackPolicy(), 1, ErrorTypeInternal, CodeInternalError, message)
+}
+
+// Wrap wraps an existing error into an AppError with a specified message.
+func Wrap(err error, message string) *AppError {
+	return wrap(GetStackPolicy(), 1, err, message)
+}
+
+// newAppError builds a new AppError using the given stack policy. The depth
+// value zero identifies the caller of newAppError.
+func newAppError(policy StackPolicy, depth int, errorType ErrorType, code, message string) *AppError {
 	code = strings.TrimSpace(code)
 	message = strings.TrimSpace(message)
 	if errorType == "" {
@@ -31,65 +66,87 @@ func NewAppError(errorType ErrorType, code, message string) *AppError {
 	if message == "" {
 		message = MsgUnknownError
 	}
-	return &AppError{
+	cause, stack := newCause(policy, depth+1, message)
+	appErr := &AppError{
 		Type:       errorType,
 		Code:       code,
 		Message:    message,
 		HTTPStatus: errorType.DefaultHTTPStatus(),
-		cause:      errors.NewWithDepth(1, message),
-	}
-}
+		cause:      cause,
 
-// New creates a new AppError with a default internal error type and message.
-func New(message string) *AppError {
-	message = strings.TrimSpace(message)
-	if message == "" {
-		message = MsgUnknownError
-	}
-	return &AppError{
-		Type:       ErrorTypeInternal,
-		Code:       CodeInternalError,
-		Message:    message,
-		HTTPStatus: StatusInternalServerError,
-		cause:      errors.NewWithDepth(1, message),
+		site: stack.site(depth + 1),
 	}
+	appErr.addStack(stack)
+	return appErr
 }
 
-// Wrap wraps an existing error into an AppError with a specified message.
-func Wrap(err error, message string) *AppError {
+// wrap wraps err into an AppError using the given stack policy. The depth
+// value zero identifies the caller of wrap.
+func wrap(policy StackPolicy, depth int, err error, message string) *AppError {
 	message = strings.TrimSpace(message)
 	if message == "" {
 		message = MsgUnknownError
 	}
 	if err == nil {
-		return &AppError{
+		cause, stack := newCause(policy, depth+1, message)
+		appErr := &AppError{
 			Type:       ErrorTypeInternal,
 			Code:       CodeInternalError,
 			Message:    message,
 			HTTPStatus: StatusInternalServerError,
-			cause:      errors.NewWithDepth(1, message),
+			cause:      cause,
+
+			site: stack.site(depth + 1),
 		}
+		appErr.addStack(stack)
+		return appErr
 	}
 	// Check if it's already an AppError - preserve original structure
 	if appErr, ok := AsAppError(err); ok {
-		return &AppError{
-			Type:       appErr.Type,
-			Code:       appErr.Code,
-			Message:    message,
-			Details:    appErr.Details,
-			HTTPStatus: appErr.HTTPStatus,
-			cause:      errors.WrapWithDepth(1, appErr.cause, message),
+		cause, stack := wrapCause(policy, depth+1, appErr.cause, message)
+		out := &AppError{
+			Type:        appErr.Type,
+			Code:        appErr.Code,
+			Message:     message,
+			Details:     appErr.Details,
+			HTTPStatus:  appErr.HTTPStatus,
+			cause:       cause,
+			fingerprint: appErr.fingerprint,
+			retryable:   appErr.retryable,
+			retryAfter:  appErr.retryAfter,
+			attempts:    appErr.attempts,
+			upstream:    appErr.upstream,
+			fields:      slices.Clip(appErr.fields),
+
+			decodedFingerprint: appErr.decodedFingerprint,
+			decodedFrames:      appErr.decodedFrames,
+			site:               appErr.site,
+			pending:            appErr.pending,
 		}
+		out.addStack(stack)
+		return out
 	}
 	// Auto-detect error type and code from the original error
 	errorType, code := detectErrorTypeAndCode(err)
-	return &AppError{
+	return wrapAs(policy, depth+1, err, errorType, code, message)
+}
+
+// wrapAs wraps err into an AppError of the given type and code, keeping err
+// as is in the cause chain. The depth value zero identifies the caller of
+// wrapAs.
+func wrapAs(policy StackPolicy, depth int, err error, errorType ErrorType, code, message string) *AppError {
+	cause, stack := wrapCause(policy, depth+1, err, message)
+	appErr := &AppError{
 		Type:       errorType,
 		Code:       code,
 		Message:    message,
 		HTTPStatus: errorType.DefaultHTTPStatus(),
-		cause:      errors.WrapWithDepth(1, err, message),
+		cause:      cause,
+
+		site: stack.site(depth + 1),
 	}
+	appErr.addStack(stack)
+	return appErr
 }
 
 // AsAppError safely converts an error to AppError if possible.
@@ -106,6 +163,21 @@ func AsAppError(err error) (*AppError, bool) {
 	return nil, false
 }
 
+// ToAppError returns the AppError in the chain of err, or classifies err as
+// Wrap does, without capturing a stack trace. It is meant for code that
+// renders or reports errors, where the stack of the caller is irrelevant.
+// A nil err yields an internal error.
+func ToAppError(err error) *AppError {
+	if appErr, ok := AsAppError(err); ok {
+		return appErr
+	}
+	policy := StackPolicy{Capture: StackCaptureNever}
+	if err == nil {
+		return newAppError(policy, 0, ErrorTypeInternal, CodeInternalError, MsgUnknownError)
+	}
+	return wrap(policy, 0, err, err.Error())
+}
+
 // IsAppError checks if an error is an AppError.
 func IsAppError(err error) bool {
 	_, ok := AsAppError(err)
@@ -203,7 +275,10 @@ func (e *AppError) WithCause(cause error) *AppError {
 		return nil
 	}
 	if cause != nil {
-		e.cause = errors.WrapWithDepth(1, cause, e.Message)
+		var stack errorStack
+		e.cause, stack = wrapCause(GetStackPolicy(), 1, cause, e.Message)
+		e.pending = nil
+		e.addStack(stack)
 	}
 	return e
 }
@@ -254,7 +329,7 @@ func (e *AppError) GetStackTrace() string {
 	if e == nil || e.cause == nil {
 		return "no stack trace available"
 	}
-	return fmt.Sprintf("%+v", e.cause)
+	return fmt.Sprintf("%+v", e.cause) + e.pendingStackTrace()
 }
 
 // GetStackTraceLines returns stack trace as a slice of strings from an error.
@@ -263,7 +338,7 @@ func (e *AppError) GetStackTraceLines() []string {
 	if e == nil || e.cause == nil {
 		return []string{"no stack trace available"}
 	}
-	stack := fmt.Sprintf("%+v", e.cause)
+	stack := fmt.Sprintf("%+v", e.cause) + e.pendingStackTrace()
 	lines := strings.Split(stack, "\n")
 
 	// Clean each line by removing leading/trailing whitespace and special characters
@@ -1,7 +1,9 @@
 package xerrs
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -120,3 +122,16 @@ func TestCause(t *testing.T) {
 	err := New("An error occurred").WithCause(cause)
 	assert.Contains(t, err.Unwrap().Error(), "root cause")
 }
+
+func TestToAppError(t *testing.T) {
+	appErr := New("boom").AsResourceNotFound()
+	assert.Same(t, appErr, ToAppError(fmt.Errorf("load: %w", appErr)))
+
+	classified := ToAppError(sql.ErrNoRows)
+	assert.Equal(t, ErrorTypeNotFound, classified.Type)
+	assert.Equal(t, sql.ErrNoRows.Error(), classified.Message)
+	assert.Empty(t, classified.StackFrames())
+	assert.True(t, errors.Is(classified, sql.ErrNoRows))
+
+	assert.Equal(t, ErrorTypeInternal, ToAppError(nil).Type)
+}
@@ -0,0 +1,227 @@
+// Package errorz keeps an in-process view of recent errors and serves it over
+// HTTP, in the style of net/http/pprof.
+//
+// The handler exposes internal error messages, so it is only served where it
+// is registered explicitly, typically on an internal debug listener:
+//
+//	errorz.Register(debugMux)
+//
+// Then visit /debug/errorz, or /debug/errorz?format=json for JSON. The
+// "type" query parameter filters by error type; a POST or DELETE request
+// clears the data.
+package errorz
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// Default sizes of a Tracker.
+const (
+	DefaultRecentSize = 100
+	DefaultMaxGroups  = 500
+	sampleStackFrames = 20
+)
+
+// Path is the path the handler is registered at by Register.
+const Path = "/debug/errorz"
+
+// Register registers the handler for the Default tracker on mux at Path.
+func Register(mux *http.ServeMux) {
+	mux.Handle(Path, Handler(Default))
+}
+
+// Default is the tracker used by Record, Middleware and the handler
+// registered by Register.
+var Default = NewTracker(DefaultRecentSize, DefaultMaxGroups)
+
+// Entry is a single recorded error occurrence.
+type Entry struct {
+	Time    time.Time       `json:"time"`
+	Type    xerrs.ErrorType `json:"type"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Path    string          `json:"path,omitempty"`
+}
+
+// Group aggregates the occurrences of errors sharing a type, code and message template.
+type Group struct {
+	Type        xerrs.ErrorType `json:"type"`
+	Code        string          `json:"code"`
+	Template    string          `json:"template"`
+	Count       int64           `json:"count"`
+	FirstSeen   time.Time       `json:"first_seen"`
+	LastSeen    time.Time       `json:"last_seen"`
+	SampleMsg   string          `json:"sample_message"`
+	SampleStack []xerrs.Frame   `json:"sample_stack,omitempty"`
+	LastPath    string          `json:"last_path,omitempty"`
+}
+
+// groupKey identifies a Group.
+type groupKey struct {
+	errorType xerrs.ErrorType
+	code      string
+	template  string
+}
+
+// Tracker records errors in a bounded ring buffer of recent occurrences and a
+// bounded set of aggregated groups. It is safe for concurrent use.
+type Tracker struct {
+	mu        sync.Mutex
+	recent    []Entry
+	next      int
+	full      bool
+	groups    map[groupKey]*Group
+	maxGroups int
+	now       func() time.Time
+}
+
+// NewTracker creates a Tracker keeping the last recentSize occurrences and at
+// most maxGroups groups. When the group limit is reached, the least recently
+// seen group is evicted.
+func NewTracker(recentSize, maxGroups int) *Tracker {
+	if recentSize <= 0 {
+		recentSize = DefaultRecentSize
+	}
+	if maxGroups <= 0 {
+		maxGroups = DefaultMaxGroups
+	}
+	return &Tracker{
+		recent:    make([]Entry, recentSize),
+		groups:    make(map[groupKey]*Group),
+		maxGroups: maxGroups,
+		now:       time.Now,
+	}
+}
+
+// Record adds err to the default tracker.
+func Record(err error) {
+	Default.Record(err, "")
+}
+
+// Record adds err, observed while serving path, to the tracker.
+func (t *Tracker) Record(err error, path string) {
+	if err == nil {
+		return
+	}
+	appErr := xerrs.ToAppError(err)
+	now := t.now()
+	key := groupKey{errorType: appErr.Type, code: appErr.Code, template: xerrs.MessageTemplate(appErr.Message)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.recent[t.next] = Entry{Time: now, Type: appErr.Type, Code: appErr.Code, Message: appErr.Message, Path: path}
+	t.next = (t.next + 1) % len(t.recent)
+	if t.next == 0 {
+		t.full = true
+	}
+
+	group, ok := t.groups[key]
+	if !ok {
+		if len(t.groups) >= t.maxGroups {
+			t.evictOldestLocked()
+		}
+		group = &Group{
+			Type:      key.errorType,
+			Code:      key.code,
+			Template:  key.template,
+			FirstSeen: now,
+			SampleMsg: appErr.Message,
+			SampleStack: appErr.StackFramesWithOptions(xerrs.StackFrameOptions{
+				TrimGOROOT:      true,
+				TrimModulePaths: true,
+				SkipRuntime:     true,
+				MaxFrames:       sampleStackFrames,
+			}),
+		}
+		t.groups[key] = group
+	}
+	group.Count++
+	group.LastSeen = now
+	if path != "" {
+		group.LastPath = path
+	}
+}
+
+// evictOldestLocked removes the least recently seen group.
+func (t *Tracker) evictOldestLocked() {
+	var oldest groupKey
+	var oldestSeen time.Time
+	first := true
+	for key, group := range t.groups {
+		if first || group.LastSeen.Before(oldestSeen) {
+			oldest, oldestSeen, first = key, group.LastSeen, false
+		}
+	}
+	delete(t.groups, oldest)
+}
+
+// Reset clears all recorded errors.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.recent)
+	t.next = 0
+	t.full = false
+	t.groups = make(map[groupKey]*Group)
+}
+
+// Snapshot is a point-in-time copy of a Tracker.
+type Snapshot struct {
+	Groups []Group `json:"groups"`
+	Recent []Entry `json:"recent"`
+}
+
+// Snapshot returns the groups, most frequent first, and the recent entries,
+// newest first. A non-empty errorType keeps only errors of that type.
+func (t *Tracker) Snapshot(errorType xerrs.ErrorType) Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snap := Snapshot{Groups: []Group{}, Recent: []Entry{}}
+	for _, group := range t.groups {
+		if errorType == "" || group.Type == errorType {
+			snap.Groups = append(snap.Groups, *group)
+		}
+	}
+	sort.Slice(snap.Groups, func(i, j int) bool {
+		if snap.Groups[i].Count != snap.Groups[j].Count {
+			return snap.Groups[i].Count > snap.Groups[j].Count
+		}
+		return snap.Groups[i].LastSeen.After(snap.Groups[j].LastSeen)
+	})
+
+	size := t.next
+	if t.full {
+		size = len(t.recent)
+	}
+	for i := 1; i <= size; i++ {
+		entry := t.recent[(t.next-i+len(t.recent))%len(t.recent)]
+		if errorType == "" || entry.Type == errorType {
+			snap.Recent = append(snap.Recent, entry)
+		}
+	}
+	return snap
+}
+
+// Middleware records the error reported with xerrs.SetRequestError for each
+// request in the default tracker, along with the request path.
+func Middleware(next http.Handler) http.Handler {
+	return MiddlewareFor(Default, next)
+}
+
+// MiddlewareFor is like Middleware with the given tracker.
+func MiddlewareFor(t *Tracker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(xerrs.WithRequestError(r.Context()))
+		next.ServeHTTP(w, r)
+		if err := xerrs.RequestError(r.Context()); err != nil {
+			t.Record(err, r.URL.Path)
+		}
+	})
+}
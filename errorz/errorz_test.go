@@ -0,0 +1,192 @@
+package errorz
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// fakeClock returns increasing times one second apart.
+func fakeClock() func() time.Time {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func newTestTracker(recentSize, maxGroups int) *Tracker {
+	t := NewTracker(recentSize, maxGroups)
+	t.now = fakeClock()
+	return t
+}
+
+func TestTracker_Groups(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	tracker.Record(xerrs.New("user 1 not found").AsResourceNotFound(), "/users/1")
+	tracker.Record(xerrs.New("user 2 not found").AsResourceNotFound(), "/users/2")
+	tracker.Record(xerrs.New("db down").AsDatabaseConnection(), "")
+
+	snap := tracker.Snapshot("")
+	require.Len(t, snap.Groups, 2)
+
+	group := snap.Groups[0]
+	assert.Equal(t, xerrs.ErrorTypeNotFound, group.Type)
+	assert.Equal(t, xerrs.CodeResourceNotFound, group.Code)
+	assert.Equal(t, "user {} not found", group.Template)
+	assert.Equal(t, int64(2), group.Count)
+	assert.Equal(t, "user 1 not found", group.SampleMsg)
+	assert.Equal(t, "/users/2", group.LastPath)
+	assert.True(t, group.LastSeen.After(group.FirstSeen))
+	assert.NotEmpty(t, group.SampleStack)
+
+	require.Len(t, snap.Recent, 3)
+	assert.Equal(t, "db down", snap.Recent[0].Message)
+	assert.Equal(t, "user 1 not found", snap.Recent[2].Message)
+}
+
+func TestTracker_RingBuffer(t *testing.T) {
+	tracker := newTestTracker(2, 10)
+	tracker.Record(errors.New("first"), "")
+	tracker.Record(errors.New("second"), "")
+	tracker.Record(errors.New("third"), "")
+
+	recent := tracker.Snapshot("").Recent
+	require.Len(t, recent, 2)
+	assert.Equal(t, "third", recent[0].Message)
+	assert.Equal(t, "second", recent[1].Message)
+}
+
+func TestTracker_EvictsOldestGroup(t *testing.T) {
+	tracker := newTestTracker(10, 2)
+	tracker.Record(xerrs.New("a").AsInvalidInput(), "")
+	tracker.Record(xerrs.New("b").AsInvalidFormat(), "")
+	tracker.Record(xerrs.New("a").AsInvalidInput(), "")
+	tracker.Record(xerrs.New("c").AsInvalidRange(), "")
+
+	var codes []string
+	for _, group := range tracker.Snapshot("").Groups {
+		codes = append(codes, group.Code)
+	}
+	assert.ElementsMatch(t, []string{xerrs.CodeInvalidInput, xerrs.CodeInvalidRange}, codes)
+}
+
+func TestTracker_FilterAndReset(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	tracker.Record(xerrs.New("bad").AsInvalidInput(), "")
+	tracker.Record(xerrs.New("down").AsServiceUnavailable(), "")
+
+	snap := tracker.Snapshot(xerrs.ErrorTypeValidation)
+	require.Len(t, snap.Groups, 1)
+	require.Len(t, snap.Recent, 1)
+	assert.Equal(t, xerrs.ErrorTypeValidation, snap.Groups[0].Type)
+
+	tracker.Reset()
+	snap = tracker.Snapshot("")
+	assert.Empty(t, snap.Groups)
+	assert.Empty(t, snap.Recent)
+}
+
+func TestHandler_JSON(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	tracker.Record(xerrs.New("bad").AsInvalidInput(), "/form")
+	tracker.Record(xerrs.New("down").AsServiceUnavailable(), "/health")
+
+	rec := httptest.NewRecorder()
+	Handler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errorz?format=json&type=validation", nil))
+
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	var snap Snapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snap))
+	require.Len(t, snap.Groups, 1)
+	assert.Equal(t, xerrs.CodeInvalidInput, snap.Groups[0].Code)
+	assert.Equal(t, "/form", snap.Groups[0].LastPath)
+}
+
+func TestHandler_HTML(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	tracker.Record(xerrs.New("<script>bad</script>").AsInvalidInput(), "/form")
+
+	rec := httptest.NewRecorder()
+	Handler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errorz", nil))
+
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, xerrs.CodeInvalidInput)
+	assert.Contains(t, body, "&lt;script&gt;bad&lt;/script&gt;")
+	assert.NotContains(t, body, "<script>bad")
+}
+
+func TestHandler_Reset(t *testing.T) {
+	tests := []struct {
+		method   string
+		expected int
+	}{
+		{http.MethodPost, http.StatusSeeOther},
+		{http.MethodDelete, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			tracker := newTestTracker(10, 10)
+			tracker.Record(xerrs.New("bad").AsInvalidInput(), "")
+
+			rec := httptest.NewRecorder()
+			Handler(tracker).ServeHTTP(rec, httptest.NewRequest(tt.method, "/debug/errorz", nil))
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Empty(t, tracker.Snapshot("").Groups)
+		})
+	}
+}
+
+func TestHandler_GetDoesNotReset(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	tracker.Record(xerrs.New("bad").AsInvalidInput(), "")
+
+	rec := httptest.NewRecorder()
+	Handler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errorz?reset=1", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, tracker.Snapshot("").Groups, 1)
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(newTestTracker(10, 10)).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/errorz", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD, POST, DELETE", rec.Header().Get("Allow"))
+}
+
+func TestMiddlewareFor(t *testing.T) {
+	tracker := newTestTracker(10, 10)
+	handler := MiddlewareFor(tracker, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xerrs.SetRequestError(r.Context(), xerrs.New("order missing").AsResourceNotFound())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+
+	snap := tracker.Snapshot("")
+	require.Len(t, snap.Recent, 1)
+	assert.Equal(t, "/orders/7", snap.Recent[0].Path)
+}
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	Register(mux)
+
+	_, pattern := mux.Handler(httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, Path, pattern)
+
+	_, pattern = http.DefaultServeMux.Handler(httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Empty(t, pattern)
+}
@@ -0,0 +1,111 @@
+package errorz
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// Handler returns an http.Handler serving the tracker as HTML, or as JSON when
+// the "format" query parameter is "json" or the request accepts only JSON.
+// GET and HEAD requests render the tracker; POST and DELETE requests clear it.
+// A POST is redirected back to the page, so the reset button of the HTML
+// page works without scripts.
+//
+// Query parameters:
+//
+//	type=VALIDATION  keep only errors of the given type
+func Handler(t *Tracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			t.Reset()
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return
+		case http.MethodDelete:
+			t.Reset()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		errorType := xerrs.ErrorType(strings.ToUpper(r.URL.Query().Get("type")))
+		snap := t.Snapshot(errorType)
+
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if wantsJSON(r) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(snap)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = pageTemplate.Execute(w, pageData{Snapshot: snap, Type: errorType})
+	})
+}
+
+// wantsJSON reports whether the request asks for the JSON rendering.
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// pageData is the data rendered by pageTemplate.
+type pageData struct {
+	Snapshot
+	Type xerrs.ErrorType
+}
+
+var pageTemplate = template.Must(template.New("errorz").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>/debug/errorz</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+pre { margin: 0; }
+</style>
+</head>
+<body>
+<h1>/debug/errorz{{if .Type}} ({{.Type}}){{end}}</h1>
+<form method="post"><a href="?">all</a> | <a href="?format=json{{if .Type}}&type={{.Type}}{{end}}">json</a> | <button type="submit">reset</button></form>
+<h2>Groups</h2>
+<table>
+<tr><th>Count</th><th>Type</th><th>Code</th><th>Message</th><th>First seen</th><th>Last seen</th><th>Last path</th><th>Sample stack</th></tr>
+{{range .Groups}}<tr>
+<td>{{.Count}}</td>
+<td><a href="?type={{.Type}}">{{.Type}}</a></td>
+<td>{{.Code}}</td>
+<td>{{.SampleMsg}}</td>
+<td>{{.FirstSeen.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.LastSeen.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.LastPath}}</td>
+<td><pre>{{range .SampleStack}}{{.}}
+{{end}}</pre></td>
+</tr>
+{{else}}<tr><td colspan="8">no errors</td></tr>
+{{end}}</table>
+<h2>Recent</h2>
+<table>
+<tr><th>Time</th><th>Type</th><th>Code</th><th>Message</th><th>Path</th></tr>
+{{range .Recent}}<tr>
+<td>{{.Time.Format "2006-01-02 15:04:05.000"}}</td>
+<td>{{.Type}}</td>
+<td>{{.Code}}</td>
+<td>{{.Message}}</td>
+<td>{{.Path}}</td>
+</tr>
+{{else}}<tr><td colspan="5">no errors</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
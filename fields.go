@@ -0,0 +1,29 @@
+package xerrs
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	// Field is the dotted path of the field, such as "address.zip" or
+	// "items.0.quantity".
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// WithFieldError adds an error for the given field.
+func (e *AppError) WithFieldError(field, code, message string) *AppError {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, FieldError{Field: field, Code: code, Message: message})
+	return e
+}
+
+// FieldErrors returns the field errors added with WithFieldError.
+func (e *AppError) FieldErrors() []FieldError {
+	if e == nil || len(e.fields) == 0 {
+		return nil
+	}
+	fields := make([]FieldError, len(e.fields))
+	copy(fields, e.fields)
+	return fields
+}
@@ -0,0 +1,75 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFieldError(t *testing.T) {
+	err := NewAppError(ErrorTypeValidation, CodeValidationError, "invalid user").
+		WithFieldError("email", CodeRequiredField, "email is required").
+		WithFieldError("address.zip", CodeInvalidFormat, "zip must have 5 digits")
+
+	expected := []FieldError{
+		{Field: "email", Code: CodeRequiredField, Message: "email is required"},
+		{Field: "address.zip", Code: CodeInvalidFormat, Message: "zip must have 5 digits"},
+	}
+	assert.Equal(t, expected, err.FieldErrors())
+
+	// The returned slice is a copy.
+	err.FieldErrors()[0].Field = "changed"
+	assert.Equal(t, "email", err.FieldErrors()[0].Field)
+
+	assert.Equal(t, expected, Wrap(err, "create user").FieldErrors())
+}
+
+func TestWithFieldError_AfterWrap(t *testing.T) {
+	inner := NewAppError(ErrorTypeValidation, CodeValidationError, "invalid user").
+		WithFieldError("f1", CodeRequiredField, "required").
+		WithFieldError("f2", CodeRequiredField, "required").
+		WithFieldError("f3", CodeRequiredField, "required")
+	outer := Wrap(inner, "create user")
+
+	outer.WithFieldError("outer", CodeInvalidInput, "invalid")
+	inner.WithFieldError("inner", CodeInvalidInput, "invalid")
+
+	fieldNames := func(e *AppError) []string {
+		var names []string
+		for _, f := range e.FieldErrors() {
+			names = append(names, f.Field)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"f1", "f2", "f3", "outer"}, fieldNames(outer))
+	assert.Equal(t, []string{"f1", "f2", "f3", "inner"}, fieldNames(inner))
+}
+
+func TestFieldErrors_Empty(t *testing.T) {
+	var nilErr *AppError
+	assert.Nil(t, nilErr.WithFieldError("email", "", "required"))
+	assert.Nil(t, nilErr.FieldErrors())
+	assert.Nil(t, New("boom").FieldErrors())
+}
+
+func TestFieldErrors_JSON(t *testing.T) {
+	err := NewAppError(ErrorTypeValidation, CodeValidationError, "invalid user").
+		WithFieldError("email", CodeRequiredField, "email is required")
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []any{map[string]any{
+		"field":   "email",
+		"code":    CodeRequiredField,
+		"message": "email is required",
+	}}, decoded["fields"])
+
+	data, marshalErr = json.Marshal(New("boom"))
+	require.NoError(t, marshalErr)
+	assert.NotContains(t, string(data), "fields")
+}
@@ -0,0 +1,198 @@
+package xerrs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors/errbase"
+)
+
+// FingerprintDefault is the placeholder that expands to the default fingerprint
+// components in a custom fingerprint, in the style of Sentry's "{{ default }}".
+const FingerprintDefault = "{{ default }}"
+
+// DefaultFingerprintFrames is the default number of in-module stack frames
+// included in a fingerprint.
+const DefaultFingerprintFrames = 3
+
+// siteFrames is the maximum number of frames of the creation site recorded
+// for fingerprints.
+const siteFrames = 8
+
+// fingerprintFrames holds the number of stack frames used by Fingerprint.
+var fingerprintFrames atomic.Int32
+
+func init() {
+	fingerprintFrames.Store(DefaultFingerprintFrames)
+}
+
+// SetFingerprintFrames sets the number of stack frames of the creation site
+// included in fingerprints, at most 8. They are taken from the stack captured
+// for the error, or from a short stack walk when the stack capture policy
+// captures none; zero excludes them and avoids the walk.
+func SetFingerprintFrames(n int) {
+	fingerprintFrames.Store(int32(min(max(n, 0), siteFrames)))
+}
+
+// errorSite is the creation site of an error: the stack trace captured for
+// the error, or program counters recorded for it. Errors record their site
+// whatever the stack capture policy, so that fingerprints do not depend on it.
+type errorSite struct {
+	trace errbase.StackTraceProvider
+	pcs   []uintptr
+}
+
+// programCounters returns the program counters of the top frames of the site,
+// at most limit.
+func (s errorSite) programCounters(limit int) []uintptr {
+	if s.trace == nil {
+		return s.pcs[:min(limit, len(s.pcs))]
+	}
+	trace := s.trace.StackTrace()
+	pcs := make([]uintptr, min(limit, len(trace)))
+	for i := range pcs {
+		pcs[i] = uintptr(trace[i])
+	}
+	return pcs
+}
+
+// captureSite records the program counters of the creation site of an error
+// without a captured stack. The depth value zero identifies the caller of
+// captureSite.
+func captureSite(depth int) []uintptr {
+	n := fingerprintFrames.Load()
+	if n == 0 {
+		return nil
+	}
+	var site [siteFrames]uintptr
+	return slices.Clone(site[:runtime.Callers(depth+2, site[:n])])
+}
+
+// fingerprintCache is a fingerprint computed by Fingerprint, with the fields
+// it was computed from.
+type fingerprintCache struct {
+	errorType ErrorType
+	code      string
+	message   string
+	frames    int32
+	value     string
+}
+
+// messageParams matches the variable parts of a message that are replaced by a
+// placeholder when building the message template.
+var messageParams = []*regexp.Regexp{
+	regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`),
+	regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`),
+	regexp.MustCompile(`'[^']*'|"[^"]*"|` + "`[^`]*`"),
+	regexp.MustCompile(`\b(0[xX])?[0-9a-fA-F]{8,}\b`),
+	regexp.MustCompile(`\d+(\.\d+)?`),
+}
+
+// MessageTemplate strips parameters such as IDs, numbers and quoted values from a
+// message, so that "user 123 not found" becomes "user {} not found".
+func MessageTemplate(message string) string {
+	for _, re := range messageParams {
+		message = re.ReplaceAllString(message, "{}")
+	}
+	return message
+}
+
+// WithFingerprint sets a custom fingerprint. Parts equal to FingerprintDefault
+// expand to the default components, so the default grouping can be extended
+// instead of replaced.
+func (e *AppError) WithFingerprint(parts ...string) *AppError {
+	if e == nil {
+		return nil
+	}
+	e.fingerprint = append([]string(nil), parts...)
+	e.decodedFingerprint = ""
+	e.cachedFingerprint.Store((*fingerprintCache)(nil))
+	return e
+}
+
+// Fingerprint returns a stable identifier for grouping occurrences of the same
+// error. By default it hashes the type, code, message template and the
+// in-module frames among the top frames of the site that created the error,
+// whatever the stack capture policy. Errors decoded from JSON keep their encoded fingerprint.
+// The fingerprint is computed on first use, and again only when the type, code
+// or message of the error change.
+func (e *AppError) Fingerprint() string {
+	if e == nil {
+		return ""
+	}
+	if e.decodedFingerprint != "" {
+		return e.decodedFingerprint
+	}
+	frames := fingerprintFrames.Load()
+	cached, _ := e.cachedFingerprint.Load().(*fingerprintCache)
+	if cached != nil && cached.errorType == e.Type && cached.code == e.Code &&
+		cached.message == e.Message && cached.frames == frames {
+		return cached.value
+	}
+	parts := e.defaultFingerprintParts(int(frames))
+	if len(e.fingerprint) > 0 {
+		custom := make([]string, 0, len(e.fingerprint))
+		for _, part := range e.fingerprint {
+			if part == FingerprintDefault {
+				custom = append(custom, parts...)
+				continue
+			}
+			custom = append(custom, part)
+		}
+		parts = custom
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	value := hex.EncodeToString(sum[:16])
+	e.cachedFingerprint.Store(&fingerprintCache{
+		errorType: e.Type,
+		code:      e.Code,
+		message:   e.Message,
+		frames:    frames,
+		value:     value,
+	})
+	return value
+}
+
+// defaultFingerprintParts returns the components hashed by a default
+// fingerprint with up to limit frames.
+func (e *AppError) defaultFingerprintParts(limit int) []string {
+	parts := []string{string(e.Type), e.Code, MessageTemplate(e.Message)}
+	if limit == 0 {
+		return parts
+	}
+	for _, frame := range resolveFrames(e.site.programCounters(limit)) {
+		if !isModuleFrame(frame) {
+			continue
+		}
+		parts = append(parts, frame.Function)
+		if limit--; limit == 0 {
+			break
+		}
+	}
+	return parts
+}
+
+// mainModulePath returns the import path of the main module, if known.
+var mainModulePath = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+})
+
+// isModuleFrame reports whether the frame belongs to the main module. Without
+// build information, every frame outside the standard library qualifies.
+func isModuleFrame(f Frame) bool {
+	if module := mainModulePath(); module != "" {
+		return f.Package == module || strings.HasPrefix(f.Package, module+"/")
+	}
+	first, _, _ := strings.Cut(f.Package, "/")
+	return strings.Contains(first, ".")
+}
@@ -0,0 +1,137 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUserNotFound(id int) *AppError {
+	return New(fmt.Sprintf("user %d not found", id)).AsResourceNotFound()
+}
+
+func TestMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{"Number", "user 123 not found", "user {} not found"},
+		{"UUID", "order 0b8e6f5c-3f3a-4c1e-9a57-2f1d0c7e9b21 missing", "order {} missing"},
+		{"Email", "invite for jane.doe@example.com expired", "invite for {} expired"},
+		{"Quoted", `field "email" is invalid`, "field {} is invalid"},
+		{"Hex", "object deadbeef01 is locked", "object {} is locked"},
+		{"Decimal", "amount 12.50 exceeds limit", "amount {} exceeds limit"},
+		{"No Params", "database unavailable", "database unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MessageTemplate(tt.message))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	first := newUserNotFound(123)
+	second := newUserNotFound(456)
+
+	assert.Len(t, first.Fingerprint(), 32)
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+	assert.Equal(t, first.Fingerprint(), first.Fingerprint())
+
+	otherCode := newUserNotFound(123).AsNotFoundWithCode("USER_NOT_FOUND")
+	assert.NotEqual(t, first.Fingerprint(), otherCode.Fingerprint())
+
+	otherSite := New("user 123 not found").AsResourceNotFound()
+	assert.NotEqual(t, first.Fingerprint(), otherSite.Fingerprint())
+}
+
+func TestFingerprint_WithoutFrames(t *testing.T) {
+	SetFingerprintFrames(0)
+	t.Cleanup(func() { SetFingerprintFrames(DefaultFingerprintFrames) })
+
+	first := newUserNotFound(123)
+	second := New("user 789 not found").AsResourceNotFound()
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+}
+
+func newUserNotFoundWithPolicy(policy StackPolicy, id int) *AppError {
+	return WithStackPolicy(policy).New(fmt.Sprintf("user %d not found", id)).AsResourceNotFound()
+}
+
+func TestFingerprint_IndependentOfStackPolicy(t *testing.T) {
+	always := newUserNotFoundWithPolicy(StackPolicy{Capture: StackCaptureAlways}, 1)
+	never := newUserNotFoundWithPolicy(StackPolicy{Capture: StackCaptureNever}, 2)
+	serverErrors := newUserNotFoundWithPolicy(StackPolicy{Capture: StackCaptureServerErrors}, 3)
+
+	assert.Empty(t, never.StackFrames())
+	assert.Equal(t, always.Fingerprint(), never.Fingerprint())
+	assert.Equal(t, always.Fingerprint(), serverErrors.Fingerprint())
+
+	otherSite := WithStackPolicy(StackPolicy{Capture: StackCaptureNever}).New("user 4 not found").AsResourceNotFound()
+	assert.NotEqual(t, never.Fingerprint(), otherSite.Fingerprint())
+}
+
+func TestWithFingerprint(t *testing.T) {
+	first := newUserNotFound(1).WithFingerprint("user-lookup")
+	second := New("completely different").WithFingerprint("user-lookup")
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+
+	extended := newUserNotFound(1).WithFingerprint(FingerprintDefault, "tenant-a")
+	assert.NotEqual(t, newUserNotFound(1).Fingerprint(), extended.Fingerprint())
+
+	wrapped := Wrap(first, "lookup failed")
+	assert.Equal(t, first.Fingerprint(), wrapped.Fingerprint())
+}
+
+func TestFingerprint_Cache(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(err *AppError)
+	}{
+		{"Message", func(err *AppError) { err.WithMessage("order 1 not found") }},
+		{"Type", func(err *AppError) { err.AsDatabaseError() }},
+		{"Code", func(err *AppError) { err.WithCode(CodeDatabaseError) }},
+		{"Custom Fingerprint", func(err *AppError) { err.WithFingerprint("user-lookup") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newUserNotFound(1)
+			before := err.Fingerprint()
+			assert.Equal(t, before, err.Fingerprint())
+
+			tt.change(err)
+			assert.NotEqual(t, before, err.Fingerprint())
+		})
+	}
+}
+
+func TestFingerprint_NilError(t *testing.T) {
+	var err *AppError
+	assert.Empty(t, err.Fingerprint())
+	assert.Nil(t, err.WithFingerprint("x"))
+}
+
+func TestFingerprint_JSON(t *testing.T) {
+	err := newUserNotFound(42)
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, err.Fingerprint(), decoded["fingerprint"])
+	assert.Equal(t, CodeResourceNotFound, decoded["code"])
+}
+
+func BenchmarkFingerprint(b *testing.B) {
+	err := newUserNotFound(1)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = err.Fingerprint()
+	}
+}
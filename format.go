@@ -0,0 +1,60 @@
+package xerrs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Format implements fmt.Formatter.
+//
+//	%v, %s  the short form, same as Error()
+//	%q      the short form, quoted
+//	%+v     the full form: header, type/code/status/details, then each wrap
+//	        layer's message and stack trace
+//	%#v     a Go-syntax representation of the error
+func (e *AppError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('#'):
+		_, _ = io.WriteString(s, e.GoString())
+	case verb == 'v' && s.Flag('+'):
+		errors.FormatError(e, s, verb)
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
+// FormatError implements errors.Formatter. The short message of the inner
+// causes is elided since the AppError header already describes the error.
+func (e *AppError) FormatError(p errors.Printer) (next error) {
+	p.Print(e.Error())
+	if p.Detail() {
+		p.Printf("type: %s\ncode: %s\nhttp status: %d", e.Type, e.Code, e.GetHTTPStatus())
+		if e.Details != "" {
+			p.Printf("\ndetails: %s", e.Details)
+		}
+		if e.upstream != nil {
+			p.Printf("\nupstream: %s", e.upstream)
+		}
+	}
+	return nil
+}
+
+// GoString implements fmt.GoStringer.
+func (e *AppError) GoString() string {
+	if e == nil {
+		return "(*xerrs.AppError)(nil)"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "&xerrs.AppError{Type:%q, Code:%q, Message:%q, Details:%q, HTTPStatus:%d",
+		e.Type, e.Code, e.Message, e.Details, e.HTTPStatus)
+	if e.cause != nil {
+		fmt.Fprintf(&b, ", cause:%q", e.cause.Error())
+	}
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package xerrs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// stackLocation matches the file:line lines of a rendered stack trace.
+var stackLocation = regexp.MustCompile(`(?m)^(\s*\|\s*)\t\S+:\d+$`)
+
+// assertGolden compares got with testdata/<name>.golden, rewriting the file
+// when the -update flag is set.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got)
+}
+
+func newFormatTestError() *AppError {
+	inner := New("connection refused").AsDatabaseConnection().WithDetails("host=db-1")
+	return Wrap(inner, "load user")
+}
+
+func TestFormat(t *testing.T) {
+	err := newFormatTestError()
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{"short_v", "%v"},
+		{"short_s", "%s"},
+		{"quoted", "%q"},
+		{"gosyntax", "%#v"},
+		{"full", "%+v"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stackLocation.ReplaceAllString(fmt.Sprintf(tt.format, err), "$1\t<file>:<line>")
+			assertGolden(t, filepath.Join("format", tt.name), got)
+		})
+	}
+}
+
+func TestFormat_FullWithoutStack(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureNever})
+
+	err := Wrap(errors.New("invalid character 'x'"), "decode request").WithDetails("body: line 1")
+	assertGolden(t, "format/full_no_stack", fmt.Sprintf("%+v", err))
+}
+
+func TestFormat_ShortMatchesError(t *testing.T) {
+	err := newFormatTestError()
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, err.Error(), fmt.Sprint(err))
+}
+
+func TestGoString_NilError(t *testing.T) {
+	var err *AppError
+	assert.Equal(t, "(*xerrs.AppError)(nil)", err.GoString())
+}
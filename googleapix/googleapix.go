@@ -0,0 +1,124 @@
+// Package googleapix renders xerrs errors in the JSON error format of Google
+// APIs, the JSON mapping of google.rpc.Status.
+package googleapix
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// ContentType is the content type of rendered errors.
+const ContentType = "application/json"
+
+// Detail type URLs.
+const (
+	TypeErrorInfo  = "type.googleapis.com/google.rpc.ErrorInfo"
+	TypeBadRequest = "type.googleapis.com/google.rpc.BadRequest"
+	TypeRetryInfo  = "type.googleapis.com/google.rpc.RetryInfo"
+	TypeDebugInfo  = "type.googleapis.com/google.rpc.DebugInfo"
+)
+
+// Response is the Google API error response.
+type Response struct {
+	Error Status `json:"error"`
+}
+
+// Status is the error of a Response.
+type Status struct {
+	// Code is the HTTP status.
+	Code int `json:"code"`
+	// Message is the error message.
+	Message string `json:"message"`
+	// Status is the google.rpc.Code name, such as NOT_FOUND.
+	Status string `json:"status"`
+	// Details holds ErrorInfo, BadRequest, RetryInfo and DebugInfo values.
+	Details []any `json:"details,omitempty"`
+}
+
+// ErrorInfo is the JSON form of google.rpc.ErrorInfo.
+type ErrorInfo struct {
+	Type     string            `json:"@type"`
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// BadRequest is the JSON form of google.rpc.BadRequest.
+type BadRequest struct {
+	Type            string           `json:"@type"`
+	FieldViolations []FieldViolation `json:"fieldViolations"`
+}
+
+// FieldViolation is the JSON form of google.rpc.BadRequest.FieldViolation.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// RetryInfo is the JSON form of google.rpc.RetryInfo.
+type RetryInfo struct {
+	Type       string `json:"@type"`
+	RetryDelay string `json:"retryDelay"`
+}
+
+// DebugInfo is the JSON form of google.rpc.DebugInfo.
+type DebugInfo struct {
+	Type   string `json:"@type"`
+	Detail string `json:"detail"`
+}
+
+// Render converts err to a Google API error response. The status comes from
+// the error type, as for gRPC. Details hold an ErrorInfo with the error code,
+// type and HTTP status, a BadRequest with the field errors, a RetryInfo with
+// RetryAfter and a DebugInfo with Details, each when present.
+func Render(err error) Response {
+	appErr := xerrs.ToAppError(err)
+	st := Status{
+		Code:    appErr.GetHTTPStatus(),
+		Message: appErr.Message,
+		Status:  xerrs.GRPCCodeName(appErr.GRPCCode()),
+		Details: []any{ErrorInfo{
+			Type:   TypeErrorInfo,
+			Reason: appErr.Code,
+			Domain: xerrs.ErrorInfoDomain,
+			Metadata: map[string]string{
+				xerrs.ErrorInfoMetadataType:       string(appErr.Type),
+				xerrs.ErrorInfoMetadataHTTPStatus: strconv.Itoa(appErr.GetHTTPStatus()),
+			},
+		}},
+	}
+	if fields := appErr.FieldErrors(); len(fields) > 0 {
+		violations := make([]FieldViolation, 0, len(fields))
+		for _, field := range fields {
+			violations = append(violations, FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+				Reason:      field.Code,
+			})
+		}
+		st.Details = append(st.Details, BadRequest{Type: TypeBadRequest, FieldViolations: violations})
+	}
+	if d := appErr.RetryAfter(); d > 0 {
+		st.Details = append(st.Details, RetryInfo{
+			Type:       TypeRetryInfo,
+			RetryDelay: strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s",
+		})
+	}
+	if appErr.Details != "" {
+		st.Details = append(st.Details, DebugInfo{Type: TypeDebugInfo, Detail: appErr.Details})
+	}
+	return Response{Error: st}
+}
+
+// WriteError writes err as a Google API error response.
+func WriteError(w http.ResponseWriter, err error) {
+	resp := Render(err)
+	xerrs.SetRetryAfterHeader(w.Header(), err)
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(resp.Error.Code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
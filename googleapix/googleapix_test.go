@@ -0,0 +1,90 @@
+package googleapix
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestRender(t *testing.T) {
+	err := xerrs.NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeValidationError, "invalid user").
+		WithFieldError("email", xerrs.CodeRequiredField, "email is required").
+		WithDetails("see the API reference")
+
+	data, marshalErr := json.Marshal(Render(err))
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, `{"error": {
+		"code": 400,
+		"message": "invalid user",
+		"status": "INVALID_ARGUMENT",
+		"details": [
+			{
+				"@type": "type.googleapis.com/google.rpc.ErrorInfo",
+				"reason": "VALIDATION_ERROR",
+				"domain": "xerrs",
+				"metadata": {"type": "VALIDATION", "http_status": "400"}
+			},
+			{
+				"@type": "type.googleapis.com/google.rpc.BadRequest",
+				"fieldViolations": [{"field": "email", "description": "email is required", "reason": "REQUIRED_FIELD"}]
+			},
+			{
+				"@type": "type.googleapis.com/google.rpc.DebugInfo",
+				"detail": "see the API reference"
+			}
+		]
+	}}`, string(data))
+}
+
+func TestRender_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     int
+		expected string
+	}{
+		{"Not Found", xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "missing"), 404, "NOT_FOUND"},
+		{"Conflict", xerrs.NewAppError(xerrs.ErrorTypeConflict, "VERSION_MISMATCH", "stale"), 409, "ABORTED"},
+		{"Canceled", xerrs.NewAppError(xerrs.ErrorTypeClientClosed, xerrs.CodeClientClosedRequest, "gone"), 499, "CANCELLED"},
+		{"Unauthenticated", xerrs.NewAppError(xerrs.ErrorTypeAuthentication, xerrs.CodeAuthRequired, "login"), 401, "UNAUTHENTICATED"},
+		{"Plain Error", errors.New("connection refused"), 502, "UNAVAILABLE"},
+		{"Nil", nil, 500, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := Render(tt.err).Error
+			assert.Equal(t, tt.code, st.Code)
+			assert.Equal(t, tt.expected, st.Status)
+		})
+	}
+}
+
+func TestRender_RetryInfo(t *testing.T) {
+	st := Render(xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow down").
+		WithRetryAfter(1500 * time.Millisecond)).Error
+
+	require.Len(t, st.Details, 2)
+	assert.Equal(t, RetryInfo{Type: TypeRetryInfo, RetryDelay: "1.5s"}, st.Details[1])
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, xerrs.NewAppError(xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable, "down").
+		WithRetryAfter(5*time.Second))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "UNAVAILABLE", resp.Error.Status)
+}
@@ -0,0 +1,145 @@
+// Package graphqlx renders xerrs errors in the GraphQL response format, with
+// the error details in extensions. It does not depend on a GraphQL library;
+// an Adapter supplies the path and locations of errors from the library used.
+package graphqlx
+
+import (
+	"errors"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// DefaultMaskedMessage replaces the message of server errors outside debug mode.
+const DefaultMaskedMessage = "internal server error"
+
+// Location is a position in a GraphQL document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an entry of the errors list of a GraphQL response.
+type Error struct {
+	Message    string      `json:"message"`
+	Path       []any       `json:"path,omitempty"`
+	Locations  []Location  `json:"locations,omitempty"`
+	Extensions *Extensions `json:"extensions,omitempty"`
+}
+
+// Extensions holds the AppError fields of an Error.
+type Extensions struct {
+	Code       string             `json:"code"`
+	Type       xerrs.ErrorType    `json:"type"`
+	HTTPStatus int                `json:"httpStatus"`
+	Fields     []xerrs.FieldError `json:"fields,omitempty"`
+}
+
+// Response is a GraphQL response. Data may be set alongside Errors for
+// partial results.
+type Response struct {
+	Data   any     `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Adapter connects the renderer to a GraphQL library.
+type Adapter interface {
+	// Locate returns the path and source locations of err, as recorded by
+	// the library when the error was returned from a resolver.
+	Locate(err error) (path []any, locations []Location)
+}
+
+// AdapterFunc adapts a function to the Adapter interface.
+type AdapterFunc func(err error) ([]any, []Location)
+
+// Locate implements Adapter.
+func (f AdapterFunc) Locate(err error) ([]any, []Location) {
+	return f(err)
+}
+
+// Options configures a Renderer.
+type Options struct {
+	// Adapter supplies the path and locations of errors. Errors have no
+	// path or locations when nil.
+	Adapter Adapter
+	// MaskedMessage replaces the message of server errors outside debug
+	// mode. Defaults to DefaultMaskedMessage.
+	MaskedMessage string
+}
+
+// Renderer converts errors to GraphQL errors.
+type Renderer struct {
+	opts Options
+}
+
+// NewRenderer returns a Renderer. A nil opts uses the defaults.
+func NewRenderer(opts *Options) *Renderer {
+	r := &Renderer{}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.MaskedMessage == "" {
+		r.opts.MaskedMessage = DefaultMaskedMessage
+	}
+	return r
+}
+
+// Render converts err to a GraphQL error. Errors that are not AppErrors are
+// classified as by xerrs.Wrap. Unless debug mode is enabled, the message of
+// server errors is replaced by the masked message so that internal details
+// do not reach clients. A nil err yields an internal error.
+func (r *Renderer) Render(err error) Error {
+	appErr := xerrs.ToAppError(err)
+	e := Error{
+		Message: appErr.Message,
+		Extensions: &Extensions{
+			Code:       appErr.Code,
+			Type:       appErr.Type,
+			HTTPStatus: appErr.GetHTTPStatus(),
+			Fields:     appErr.FieldErrors(),
+		},
+	}
+	if appErr.IsServerError() && !xerrs.DebugMode() {
+		e.Message = r.opts.MaskedMessage
+	}
+	if r.opts.Adapter != nil && err != nil {
+		e.Path, e.Locations = r.opts.Adapter.Locate(err)
+	}
+	return e
+}
+
+// RenderAll converts errors to GraphQL errors, one per error. Errors joined
+// with errors.Join are rendered separately; nil errors are skipped.
+func (r *Renderer) RenderAll(errs ...error) []Error {
+	var out []Error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if joined := split(err); joined != nil {
+			out = append(out, r.RenderAll(joined...)...)
+			continue
+		}
+		out = append(out, r.Render(err))
+	}
+	return out
+}
+
+// Response returns a GraphQL response with data and the rendered errors.
+// Data is kept for partial results.
+func (r *Renderer) Response(data any, errs ...error) Response {
+	return Response{Data: data, Errors: r.RenderAll(errs...)}
+}
+
+// split returns the errors joined in err, or nil if err is not a joined
+// error. An AppError is never split, even when its cause is a joined error.
+func split(err error) []error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch e := e.(type) {
+		case *xerrs.AppError:
+			return nil
+		case interface{ Unwrap() []error }:
+			return e.Unwrap()
+		}
+	}
+	return nil
+}
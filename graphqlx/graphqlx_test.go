@@ -0,0 +1,149 @@
+package graphqlx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func withDebugMode(tb testing.TB, enabled bool) {
+	tb.Helper()
+	previous := xerrs.DebugMode()
+	xerrs.SetDebugMode(enabled)
+	tb.Cleanup(func() { xerrs.SetDebugMode(previous) })
+}
+
+// resolverError mimics the error type of a GraphQL library.
+type resolverError struct {
+	err  error
+	path []any
+}
+
+func (e *resolverError) Error() string { return e.err.Error() }
+func (e *resolverError) Unwrap() error { return e.err }
+
+var testAdapter = AdapterFunc(func(err error) ([]any, []Location) {
+	var re *resolverError
+	if errors.As(err, &re) {
+		return re.path, []Location{{Line: 2, Column: 3}}
+	}
+	return nil, nil
+})
+
+func TestRender(t *testing.T) {
+	err := xerrs.NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeValidationError, "invalid input").
+		WithFieldError("email", xerrs.CodeRequiredField, "email is required")
+
+	got := NewRenderer(&Options{Adapter: testAdapter}).Render(&resolverError{err: err, path: []any{"createUser", 0}})
+
+	assert.Equal(t, Error{
+		Message:   "invalid input",
+		Path:      []any{"createUser", 0},
+		Locations: []Location{{Line: 2, Column: 3}},
+		Extensions: &Extensions{
+			Code:       xerrs.CodeValidationError,
+			Type:       xerrs.ErrorTypeValidation,
+			HTTPStatus: 400,
+			Fields:     []xerrs.FieldError{{Field: "email", Code: xerrs.CodeRequiredField, Message: "email is required"}},
+		},
+	}, got)
+}
+
+func TestRender_JSON(t *testing.T) {
+	got := NewRenderer(nil).Render(xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "user not found"))
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"message": "user not found",
+		"extensions": {"code": "RESOURCE_NOT_FOUND", "type": "NOT_FOUND", "httpStatus": 404}
+	}`, string(data))
+}
+
+func TestRender_Masking(t *testing.T) {
+	const dependencyDown xerrs.ErrorType = "GRAPHQLX_DEPENDENCY_DOWN"
+	xerrs.MustRegisterErrorType(dependencyDown, xerrs.ErrorTypeOptions{HTTPStatus: http.StatusFailedDependency, Class: xerrs.ClassServer})
+
+	tests := []struct {
+		name     string
+		err      error
+		debug    bool
+		expected string
+	}{
+		{"Server Error", xerrs.New("connection to 10.0.0.5 refused"), false, DefaultMaskedMessage},
+		{"Plain Error", errors.New("pq: relation users does not exist"), false, DefaultMaskedMessage},
+		{"Debug Mode", xerrs.New("connection to 10.0.0.5 refused"), true, "connection to 10.0.0.5 refused"},
+		{"Client Error", xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "user not found"), false, "user not found"},
+		{"Registered Server Class", xerrs.NewAppError(dependencyDown, "DEPENDENCY", "db at 10.0.0.5 down"), false, DefaultMaskedMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withDebugMode(t, tt.debug)
+			got := NewRenderer(nil).Render(tt.err)
+			assert.Equal(t, tt.expected, got.Message)
+		})
+	}
+
+	withDebugMode(t, false)
+	got := NewRenderer(&Options{MaskedMessage: "something went wrong"}).Render(xerrs.New("boom"))
+	assert.Equal(t, "something went wrong", got.Message)
+	assert.Equal(t, xerrs.CodeInternalError, got.Extensions.Code)
+}
+
+func TestRender_Nil(t *testing.T) {
+	withDebugMode(t, false)
+	locate := AdapterFunc(func(err error) ([]any, []Location) {
+		return []any{err.Error()}, nil
+	})
+	got := NewRenderer(&Options{Adapter: locate}).Render(nil)
+
+	assert.Equal(t, DefaultMaskedMessage, got.Message)
+	assert.Equal(t, xerrs.ErrorTypeInternal, got.Extensions.Type)
+	assert.Equal(t, xerrs.CodeInternalError, got.Extensions.Code)
+	assert.Nil(t, got.Path)
+}
+
+func TestRenderAll(t *testing.T) {
+	notFound := xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "user not found")
+	forbidden := xerrs.NewAppError(xerrs.ErrorTypeAuthorization, xerrs.CodeAccessDenied, "access denied")
+	wrappedJoin := xerrs.Wrap(errors.Join(errors.New("a"), errors.New("b")), "batch failed").AsInvalidInput()
+
+	got := NewRenderer(nil).RenderAll(
+		nil,
+		notFound,
+		fmt.Errorf("resolve: %w", errors.Join(forbidden, nil)),
+		wrappedJoin,
+	)
+
+	require.Len(t, got, 3)
+	assert.Equal(t, "user not found", got[0].Message)
+	assert.Equal(t, "access denied", got[1].Message)
+	assert.Equal(t, "batch failed", got[2].Message)
+	assert.Nil(t, NewRenderer(nil).RenderAll())
+}
+
+func TestResponse(t *testing.T) {
+	data := map[string]any{"user": map[string]any{"name": "Ann"}, "orders": nil}
+	resp := NewRenderer(nil).Response(data, xerrs.NewAppError(xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable, "orders unavailable"))
+
+	out, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": {"user": {"name": "Ann"}, "orders": null},
+		"errors": [{
+			"message": "internal server error",
+			"extensions": {"code": "SERVICE_UNAVAILABLE", "type": "UNAVAILABLE", "httpStatus": 503}
+		}]
+	}`, string(out))
+
+	out, err = json.Marshal(NewRenderer(nil).Response(data))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "errors")
+}
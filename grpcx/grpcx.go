@@ -0,0 +1,229 @@
+// Package grpcx converts xerrs errors to and from gRPC statuses, and provides
+// interceptors that apply the conversion on servers and clients.
+package grpcx
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// ErrorInfoDomain is the domain of the ErrorInfo details added by ToStatus.
+const ErrorInfoDomain = xerrs.ErrorInfoDomain
+
+// ErrorInfo metadata keys.
+const (
+	MetadataType       = xerrs.ErrorInfoMetadataType
+	MetadataHTTPStatus = xerrs.ErrorInfoMetadataHTTPStatus
+)
+
+// stackFrames is the number of stack frames included in DebugInfo in debug mode.
+const stackFrames = 20
+
+// ToStatus converts err to a gRPC status. The code comes from the error type,
+// as registered with xerrs.RegisterErrorType; conflicts other than
+// RESOURCE_EXISTS and transient database conflicts become Aborted. The error
+// code, type and HTTP status are carried in an ErrorInfo detail, Details in a
+// DebugInfo detail, with the stack trace in debug mode, and RetryAfter in a
+// RetryInfo detail. Errors that already carry a status and are not AppErrors
+// are returned as is. A nil err yields an OK status.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	appErr, ok := xerrs.AsAppError(err)
+	if !ok {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err)
+		}
+		appErr = xerrs.ToAppError(err)
+	}
+
+	st := status.New(codes.Code(appErr.GRPCCode()), appErr.Message)
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: appErr.Code,
+			Domain: ErrorInfoDomain,
+			Metadata: map[string]string{
+				MetadataType:       string(appErr.Type),
+				MetadataHTTPStatus: strconv.Itoa(appErr.GetHTTPStatus()),
+			},
+		},
+	}
+	if debug := debugInfo(appErr); debug != nil {
+		details = append(details, debug)
+	}
+	if d := appErr.RetryAfter(); d > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// debugInfo returns the DebugInfo detail for an AppError, or nil when there
+// is nothing to report.
+func debugInfo(appErr *xerrs.AppError) *errdetails.DebugInfo {
+	info := &errdetails.DebugInfo{Detail: appErr.Details}
+	if xerrs.DebugMode() {
+		for _, frame := range appErr.StackFramesWithOptions(xerrs.StackFrameOptions{
+			TrimGOROOT:      true,
+			TrimModulePaths: true,
+			SkipRuntime:     true,
+			MaxFrames:       stackFrames,
+		}) {
+			info.StackEntries = append(info.StackEntries, frame.String())
+		}
+	}
+	if info.Detail == "" && len(info.StackEntries) == 0 {
+		return nil
+	}
+	return info
+}
+
+// FromStatus converts a gRPC status to an *AppError. The type, code and HTTP
+// status are read from the ErrorInfo detail added by ToStatus; statuses from
+// other servers are classified by their gRPC code. The status error is kept
+// as the cause, so status.Code still works on the result. It returns nil for
+// nil and OK statuses.
+func FromStatus(st *status.Status) *xerrs.AppError {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	mapping := xerrs.GRPCCodeMapping(uint32(st.Code()))
+	httpStatus := 0
+	var details string
+	var retryAfter *durationpb.Duration
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != ErrorInfoDomain {
+				continue
+			}
+			if t := d.GetMetadata()[MetadataType]; t != "" {
+				mapping.Type = xerrs.ErrorType(t)
+			}
+			if d.GetReason() != "" {
+				mapping.Code = d.GetReason()
+			}
+			httpStatus, _ = strconv.Atoi(d.GetMetadata()[MetadataHTTPStatus])
+		case *errdetails.DebugInfo:
+			details = d.GetDetail()
+		case *errdetails.RetryInfo:
+			retryAfter = d.GetRetryDelay()
+		}
+	}
+	if httpStatus == 0 {
+		httpStatus = mapping.Type.DefaultHTTPStatus()
+	}
+
+	appErr := xerrs.Wrap(st.Err(), st.Message()).
+		WithType(mapping.Type).
+		WithCode(mapping.Code).
+		WithHTTPStatus(httpStatus).
+		WithDetails(details)
+	if retryAfter != nil {
+		appErr.WithRetryAfter(retryAfter.AsDuration())
+	}
+	return appErr
+}
+
+// FromError converts an error returned by a gRPC call to an *AppError. Errors
+// without a gRPC status are returned unchanged.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if appErr := FromStatus(st); appErr != nil {
+		return appErr
+	}
+	return err
+}
+
+// UnaryServerInterceptor returns a server interceptor that converts handler
+// errors to statuses with ToStatus.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that converts stream
+// handler errors to statuses with ToStatus.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that converts call
+// errors to *AppError with FromError.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that converts stream
+// errors to *AppError with FromError. io.EOF is returned unchanged.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+// clientStream converts the errors of a grpc.ClientStream.
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	return md, streamError(err)
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return streamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return streamError(s.ClientStream.RecvMsg(m))
+}
+
+// streamError converts a stream error, leaving io.EOF unchanged.
+func streamError(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	return FromError(err)
+}
@@ -0,0 +1,240 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestToStatusCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"validation", xerrs.NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput, "bad"), codes.InvalidArgument},
+		{"authentication", xerrs.NewAppError(xerrs.ErrorTypeAuthentication, xerrs.CodeAuthRequired, "login"), codes.Unauthenticated},
+		{"authorization", xerrs.NewAppError(xerrs.ErrorTypeAuthorization, xerrs.CodeAccessDenied, "no"), codes.PermissionDenied},
+		{"not found", xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "missing"), codes.NotFound},
+		{"conflict exists", xerrs.NewAppError(xerrs.ErrorTypeConflict, xerrs.CodeResourceExists, "dup"), codes.AlreadyExists},
+		{"conflict other", xerrs.NewAppError(xerrs.ErrorTypeConflict, "VERSION_MISMATCH", "stale"), codes.Aborted},
+		{"deadlock", xerrs.NewAppError(xerrs.ErrorTypeInternal, xerrs.CodeDatabaseDeadlock, "deadlock"), codes.Aborted},
+		{"rate limit", xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow down"), codes.ResourceExhausted},
+		{"unavailable", xerrs.NewAppError(xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable, "down"), codes.Unavailable},
+		{"external", xerrs.NewAppError(xerrs.ErrorTypeExternal, xerrs.CodeExternalError, "upstream"), codes.Unavailable},
+		{"internal", xerrs.New("boom"), codes.Internal},
+		{"plain error", errors.New("duplicate key value"), codes.AlreadyExists},
+		{"canceled", context.Canceled, codes.Canceled},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"existing status", status.Error(codes.DataLoss, "lost"), codes.DataLoss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ToStatus(tt.err).Code())
+		})
+	}
+}
+
+func TestToStatusDetails(t *testing.T) {
+	err := xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow down").
+		WithDetails("limit is 10 per second").
+		WithRetryAfter(3 * time.Second)
+
+	st := ToStatus(err)
+	assert.Equal(t, "slow down", st.Message())
+
+	var info *errdetails.ErrorInfo
+	var debug *errdetails.DebugInfo
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.DebugInfo:
+			debug = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	require.NotNil(t, info)
+	assert.Equal(t, xerrs.CodeRateLimitExceeded, info.GetReason())
+	assert.Equal(t, ErrorInfoDomain, info.GetDomain())
+	assert.Equal(t, string(xerrs.ErrorTypeRateLimit), info.GetMetadata()[MetadataType])
+	assert.Equal(t, "429", info.GetMetadata()[MetadataHTTPStatus])
+	require.NotNil(t, debug)
+	assert.Equal(t, "limit is 10 per second", debug.GetDetail())
+	assert.Empty(t, debug.GetStackEntries())
+	require.NotNil(t, retry)
+	assert.Equal(t, 3*time.Second, retry.GetRetryDelay().AsDuration())
+}
+
+func TestToStatusDebugStack(t *testing.T) {
+	xerrs.SetDebugMode(true)
+	t.Cleanup(func() { xerrs.SetDebugMode(false) })
+
+	st := ToStatus(xerrs.New("boom"))
+	for _, detail := range st.Details() {
+		if debug, ok := detail.(*errdetails.DebugInfo); ok {
+			assert.NotEmpty(t, debug.GetStackEntries())
+			return
+		}
+	}
+	t.Fatal("missing DebugInfo detail")
+}
+
+func TestFromStatusRoundTrip(t *testing.T) {
+	original := xerrs.NewAppError(xerrs.ErrorTypeConflict, "VERSION_MISMATCH", "version mismatch").
+		WithDetails("expected version 3").
+		WithHTTPStatus(http.StatusPreconditionFailed).
+		WithRetryAfter(time.Second)
+
+	st := ToStatus(original)
+	got := FromStatus(st)
+	require.NotNil(t, got)
+
+	assert.Equal(t, xerrs.ErrorTypeConflict, got.Type)
+	assert.Equal(t, "VERSION_MISMATCH", got.Code)
+	assert.Equal(t, "version mismatch", got.Message)
+	assert.Equal(t, "expected version 3", got.Details)
+	assert.Equal(t, http.StatusPreconditionFailed, got.GetHTTPStatus())
+	assert.Equal(t, time.Second, got.RetryAfter())
+	assert.Equal(t, codes.Aborted, status.Code(got))
+}
+
+func TestFromStatusForeign(t *testing.T) {
+	tests := []struct {
+		code     codes.Code
+		wantType xerrs.ErrorType
+		wantCode string
+	}{
+		{codes.InvalidArgument, xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput},
+		{codes.OutOfRange, xerrs.ErrorTypeValidation, xerrs.CodeInvalidRange},
+		{codes.Unauthenticated, xerrs.ErrorTypeAuthentication, xerrs.CodeAuthRequired},
+		{codes.PermissionDenied, xerrs.ErrorTypeAuthorization, xerrs.CodeAccessDenied},
+		{codes.NotFound, xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound},
+		{codes.AlreadyExists, xerrs.ErrorTypeConflict, xerrs.CodeResourceExists},
+		{codes.FailedPrecondition, xerrs.ErrorTypePreconditionFailed, xerrs.CodePreconditionFailed},
+		{codes.ResourceExhausted, xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded},
+		{codes.Canceled, xerrs.ErrorTypeClientClosed, xerrs.CodeClientClosedRequest},
+		{codes.DeadlineExceeded, xerrs.ErrorTypeGatewayTimeout, xerrs.CodeGatewayTimeout},
+		{codes.Unimplemented, xerrs.ErrorTypeNotImplemented, xerrs.CodeNotImplemented},
+		{codes.Unavailable, xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable},
+		{codes.DataLoss, xerrs.ErrorTypeInternal, xerrs.CodeInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			got := FromStatus(status.New(tt.code, "failed"))
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantType, got.Type)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, tt.wantType.DefaultHTTPStatus(), got.GetHTTPStatus())
+			assert.Equal(t, "failed", got.Message)
+			assert.Equal(t, tt.code, status.Code(got))
+		})
+	}
+}
+
+func TestFromStatusOK(t *testing.T) {
+	assert.Nil(t, FromStatus(nil))
+	assert.Nil(t, FromStatus(status.New(codes.OK, "")))
+	assert.NoError(t, FromError(nil))
+
+	plain := errors.New("plain")
+	assert.Same(t, plain, FromError(plain))
+}
+
+// healthServer fails every call with err.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func newHealthClient(t *testing.T, err error) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(srv, &healthServer{err: err})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, dialErr := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	require.NoError(t, dialErr)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	client := newHealthClient(t, xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "service not found").
+		WithDetails("service payments"))
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Error(t, err)
+
+	appErr, ok := xerrs.AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, xerrs.ErrorTypeNotFound, appErr.Type)
+	assert.Equal(t, xerrs.CodeResourceNotFound, appErr.Code)
+	assert.Equal(t, "service not found", appErr.Message)
+	assert.Equal(t, "service payments", appErr.Details)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	client := newHealthClient(t, xerrs.NewAppError(xerrs.ErrorTypeUnavailable, xerrs.CodeServiceUnavailable, "shutting down"))
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+	appErr, ok := xerrs.AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, xerrs.ErrorTypeUnavailable, appErr.Type)
+	assert.Equal(t, xerrs.CodeServiceUnavailable, appErr.Code)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestStreamErrorEOF(t *testing.T) {
+	assert.Equal(t, io.EOF, streamError(io.EOF))
+	assert.NoError(t, streamError(nil))
+}
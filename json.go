@@ -0,0 +1,188 @@
+package xerrs
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// JSONOptions selects the optional members of the JSON encoding produced by
+// MarshalJSONWithOptions, used to pass errors through queues or caches.
+type JSONOptions struct {
+	// Causes includes the cause chain: the message of each wrap layer, and
+	// the type, code, details and HTTP status of nested AppErrors.
+	Causes bool
+	// Stack includes the stack frames.
+	Stack bool
+}
+
+// appErrorJSON is the JSON representation of an AppError.
+type appErrorJSON struct {
+	Type        ErrorType     `json:"type"`
+	Code        string        `json:"code"`
+	Message     string        `json:"message"`
+	Details     string        `json:"details,omitempty"`
+	HTTPStatus  int           `json:"http_status,omitempty"`
+	Fingerprint string        `json:"fingerprint,omitempty"`
+	Fields      []FieldError  `json:"fields,omitempty"`
+	Upstream    *upstreamJSON `json:"upstream,omitempty"`
+	Causes      []causeJSON   `json:"causes,omitempty"`
+	Stack       []Frame       `json:"stack,omitempty"`
+}
+
+// upstreamJSON is the JSON representation of an Upstream.
+type upstreamJSON struct {
+	Service   string `json:"service"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+}
+
+// causeJSON is the JSON representation of a layer of the cause chain. Type
+// and code are set for nested AppErrors.
+type causeJSON struct {
+	Message    string    `json:"message"`
+	Type       ErrorType `json:"type,omitempty"`
+	Code       string    `json:"code,omitempty"`
+	Details    string    `json:"details,omitempty"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *AppError) MarshalJSON() ([]byte, error) {
+	return e.MarshalJSONWithOptions(JSONOptions{})
+}
+
+// MarshalJSONWithOptions returns the JSON encoding of the error with the
+// optional members selected by opts. UnmarshalJSON restores them.
+func (e *AppError) MarshalJSONWithOptions(opts JSONOptions) ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	out := appErrorJSON{
+		Type:        e.Type,
+		Code:        e.Code,
+		Message:     e.Message,
+		Details:     e.Details,
+		HTTPStatus:  e.HTTPStatus,
+		Fingerprint: e.Fingerprint(),
+		Fields:      e.fields,
+	}
+	// Upstream attribution reveals internal topology, so it is only
+	// exposed in debug mode.
+	if e.upstream != nil && DebugMode() {
+		out.Upstream = &upstreamJSON{
+			Service:   e.upstream.Service,
+			Endpoint:  e.upstream.Endpoint,
+			Status:    e.upstream.Status,
+			LatencyMS: e.upstream.Latency.Milliseconds(),
+		}
+	}
+	if opts.Causes {
+		out.Causes = encodeCauses(e)
+	}
+	if opts.Stack {
+		out.Stack = e.StackFrames()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The decoded error keeps the
+// fingerprint and, when encoded, the cause chain and stack frames; nested
+// AppErrors are decoded as AppErrors in the chain.
+func (e *AppError) UnmarshalJSON(data []byte) error {
+	var in appErrorJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	decoded := decodedAppError(in.Type, in.Code, in.Message, in.Details, in.HTTPStatus, in.Fields)
+	decoded.cause = decodeCauses(in.Causes, decoded.Message)
+	decoded.decodedFingerprint = in.Fingerprint
+	decoded.decodedFrames = in.Stack
+	if u := in.Upstream; u != nil {
+		decoded.upstream = &Upstream{
+			Service:  u.Service,
+			Endpoint: u.Endpoint,
+			Status:   u.Status,
+			Latency:  time.Duration(u.LatencyMS) * time.Millisecond,
+		}
+	}
+	*e = *decoded
+	return nil
+}
+
+// encodeCauses returns the layers of the cause chain of e, outermost first.
+// Layers without a message of their own, such as stack trace wrappers, and
+// messages repeating the one of the enclosing AppError are left out.
+func encodeCauses(e *AppError) []causeJSON {
+	var causes []causeJSON
+	enclosing := e.Message
+	for err := e.cause; err != nil; {
+		if appErr, ok := err.(*AppError); ok {
+			causes = append(causes, causeJSON{
+				Message:    appErr.Message,
+				Type:       appErr.Type,
+				Code:       appErr.Code,
+				Details:    appErr.Details,
+				HTTPStatus: appErr.HTTPStatus,
+			})
+			enclosing = appErr.Message
+			err = appErr.cause
+			continue
+		}
+		next := errors.UnwrapOnce(err)
+		if message := ownMessage(err, next); message != "" {
+			if message != enclosing {
+				causes = append(causes, causeJSON{Message: message})
+			}
+			enclosing = ""
+		}
+		err = next
+	}
+	return causes
+}
+
+// ownMessage returns the part of the message of err that next does not
+// provide, such as "load user" for "load user: connection refused".
+func ownMessage(err, next error) string {
+	message := err.Error()
+	if next == nil {
+		return message
+	}
+	nextMessage := next.Error()
+	if message == nextMessage {
+		return ""
+	}
+	if own, ok := strings.CutSuffix(message, ": "+nextMessage); ok {
+		return own
+	}
+	return message
+}
+
+// decodeCauses rebuilds the cause chain of an AppError with the given
+// message from its encoded layers.
+func decodeCauses(causes []causeJSON, message string) error {
+	var cause error
+	for i := len(causes) - 1; i >= 0; i-- {
+		c := causes[i]
+		switch {
+		case c.Type != "" || c.Code != "":
+			appErr := decodedAppError(c.Type, c.Code, c.Message, c.Details, c.HTTPStatus, nil)
+			if cause != nil {
+				appErr.cause = errors.WithMessage(cause, appErr.Message)
+			}
+			cause = appErr
+		case cause == nil:
+			cause = stderrors.New(c.Message)
+		default:
+			cause = errors.WithMessage(cause, c.Message)
+		}
+	}
+	if cause == nil {
+		return stderrors.New(message)
+	}
+	return errors.WithMessage(cause, message)
+}
@@ -0,0 +1,132 @@
+package xerrs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSON_Default(t *testing.T) {
+	err := Wrap(sql.ErrNoRows, "load user").WithDetails("id=42")
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "NOT_FOUND", decoded["type"])
+	assert.Equal(t, "load user", decoded["message"])
+	assert.NotContains(t, decoded, "causes")
+	assert.NotContains(t, decoded, "stack")
+}
+
+func TestMarshalJSONWithOptions_Causes(t *testing.T) {
+	inner := NewAppError(ErrorTypeUnavailable, CodeServiceUnavailable, "payments down").
+		WithDetails("status 503").
+		WithCause(fmt.Errorf("call payments: %w", sql.ErrConnDone))
+	cause, _ := wrapCause(StackPolicy{}, 0, Wrap(inner, "charge card"), "charge failed")
+	outer := &AppError{
+		Type:       ErrorTypeExternal,
+		Code:       CodeExternalError,
+		Message:    "charge failed",
+		HTTPStatus: http.StatusBadGateway,
+		cause:      cause,
+	}
+
+	data, err := outer.MarshalJSONWithOptions(JSONOptions{Causes: true})
+	require.NoError(t, err)
+
+	var decoded struct {
+		Causes []causeJSON `json:"causes"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []causeJSON{
+		{Message: "charge card", Type: ErrorTypeUnavailable, Code: CodeServiceUnavailable, Details: "status 503", HTTPStatus: http.StatusServiceUnavailable},
+		{Message: "payments down"},
+		{Message: "call payments"},
+		{Message: sql.ErrConnDone.Error()},
+	}, decoded.Causes)
+}
+
+func TestUnmarshalJSON_RoundTrip(t *testing.T) {
+	original := Wrap(
+		NewAppError(ErrorTypeConflict, CodeResourceExists, "email taken").WithDetails("email=a@b.c"),
+		"create user",
+	).WithHTTPStatus(http.StatusUnprocessableEntity).
+		WithFieldError("email", CodeResourceExists, "already registered")
+
+	data, err := original.MarshalJSONWithOptions(JSONOptions{Causes: true, Stack: true})
+	require.NoError(t, err)
+
+	var decoded AppError
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	appErr, ok := AsAppError(fmt.Errorf("consume: %w", &decoded))
+	require.True(t, ok)
+	assert.True(t, appErr.IsType(ErrorTypeConflict))
+	assert.True(t, appErr.HasCode(CodeResourceExists))
+	assert.Equal(t, "create user", appErr.Message)
+	assert.Equal(t, "email=a@b.c", appErr.Details)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.GetHTTPStatus())
+	assert.Equal(t, original.FieldErrors(), appErr.FieldErrors())
+	assert.Equal(t, original.Error(), appErr.Error())
+	assert.Equal(t, original.Fingerprint(), appErr.Fingerprint())
+	assert.Equal(t, original.StackFrames(), appErr.StackFrames())
+	assert.Equal(t, "create user: email taken", appErr.Cause().Error())
+
+	// Decoding again yields the same encoding.
+	again, err := appErr.MarshalJSONWithOptions(JSONOptions{Causes: true, Stack: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+func TestUnmarshalJSON_NestedAppError(t *testing.T) {
+	inner := NewAppError(ErrorTypeRateLimit, CodeRateLimitExceeded, "quota exceeded")
+	outer := New("sync failed").AsExternalServiceUnavailable()
+	outer.cause, _ = wrapCause(StackPolicy{}, 0, inner, outer.Message)
+
+	data, err := outer.MarshalJSONWithOptions(JSONOptions{Causes: true})
+	require.NoError(t, err)
+	var decoded AppError
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	nested, ok := AsAppError(decoded.Unwrap())
+	require.True(t, ok)
+	assert.Equal(t, ErrorTypeRateLimit, nested.Type)
+	assert.Equal(t, CodeRateLimitExceeded, nested.Code)
+	assert.Equal(t, http.StatusTooManyRequests, nested.GetHTTPStatus())
+}
+
+func TestUnmarshalJSON_Minimal(t *testing.T) {
+	var decoded AppError
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "NOT_FOUND", "code": "RESOURCE_NOT_FOUND", "message": "missing"}`), &decoded))
+
+	assert.Equal(t, http.StatusNotFound, decoded.GetHTTPStatus())
+	assert.Equal(t, "missing", decoded.Cause().Error())
+	assert.Empty(t, decoded.StackFrames())
+	assert.NotEmpty(t, decoded.Fingerprint())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type": 1}`), &decoded))
+}
+
+func TestUnmarshalJSON_Upstream(t *testing.T) {
+	withDebugMode(t, true)
+	original := New("charge failed").AsServiceTimeout().WithUpstream(Upstream{
+		Service: "payments", Endpoint: "POST /v1/charges", Status: 504, Latency: 1200 * time.Millisecond,
+	})
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+	var decoded AppError
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	u, ok := decoded.Upstream()
+	require.True(t, ok)
+	assert.Equal(t, "payments POST /v1/charges (status 504, 1.2s)", u.String())
+}
@@ -0,0 +1,135 @@
+// Package jsonapix renders xerrs errors as JSON:API error documents.
+package jsonapix
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// ContentType is the JSON:API media type.
+const ContentType = "application/vnd.api+json"
+
+// DefaultPointerPrefix is the JSON pointer prefix of field errors.
+const DefaultPointerPrefix = "/data/attributes"
+
+// Document is a JSON:API error document.
+type Document struct {
+	Errors []Error `json:"errors"`
+}
+
+// Error is a JSON:API error object.
+type Error struct {
+	Status string         `json:"status"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *Source        `json:"source,omitempty"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// Source identifies the part of the request that caused an error.
+type Source struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// MetaType is the meta key holding the error type.
+const MetaType = "type"
+
+// Render converts errors to a JSON:API error document. An error with field
+// errors yields one error object per field, with a source pointer such as
+// "/data/attributes/address/zip"; other errors yield a single object. Nil
+// errors are skipped.
+func Render(errs ...error) Document {
+	doc := Document{Errors: []Error{}}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		doc.Errors = append(doc.Errors, render(xerrs.ToAppError(err))...)
+	}
+	return doc
+}
+
+// render converts an AppError to JSON:API error objects.
+func render(appErr *xerrs.AppError) []Error {
+	base := Error{
+		Status: strconv.Itoa(appErr.GetHTTPStatus()),
+		Code:   appErr.Code,
+		Title:  appErr.Message,
+		Detail: appErr.Details,
+		Meta:   map[string]any{MetaType: appErr.Type},
+	}
+	fields := appErr.FieldErrors()
+	if len(fields) == 0 {
+		return []Error{base}
+	}
+	out := make([]Error, 0, len(fields))
+	for _, field := range fields {
+		e := base
+		if field.Code != "" {
+			e.Code = field.Code
+		}
+		e.Detail = field.Message
+		e.Source = &Source{Pointer: Pointer(field.Field)}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Status returns the HTTP status of a response reporting errs: their status
+// when they all share it, 400 when they are all client errors, or 500.
+func Status(errs ...error) int {
+	status := 0
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		s := xerrs.ToAppError(err).GetHTTPStatus()
+		switch {
+		case status == 0 || status == s:
+			status = s
+		case status < 500 && s < 500:
+			status = http.StatusBadRequest
+		default:
+			return http.StatusInternalServerError
+		}
+	}
+	if status == 0 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// WriteError writes errs as a JSON:API error document with the status
+// returned by Status.
+func WriteError(w http.ResponseWriter, errs ...error) {
+	for _, err := range errs {
+		if xerrs.SetRetryAfterHeader(w.Header(), err) {
+			break
+		}
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(Status(errs...))
+	_ = json.NewEncoder(w).Encode(Render(errs...))
+}
+
+// Pointer returns the JSON pointer of a dotted field path, such as
+// "/data/attributes/items/0/quantity" for "items.0.quantity".
+func Pointer(field string) string {
+	var b strings.Builder
+	b.WriteString(DefaultPointerPrefix)
+	for _, part := range strings.Split(field, ".") {
+		b.WriteString("/")
+		b.WriteString(pointerEscaper.Replace(part))
+	}
+	return b.String()
+}
+
+// pointerEscaper escapes JSON pointer reference tokens (RFC 6901).
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
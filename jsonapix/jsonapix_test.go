@@ -0,0 +1,112 @@
+package jsonapix
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestRender(t *testing.T) {
+	err := xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "user not found").
+		WithDetails("no user with id 42")
+
+	data, marshalErr := json.Marshal(Render(err))
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, `{"errors": [{
+		"status": "404",
+		"code": "RESOURCE_NOT_FOUND",
+		"title": "user not found",
+		"detail": "no user with id 42",
+		"meta": {"type": "NOT_FOUND"}
+	}]}`, string(data))
+}
+
+func TestRender_FieldErrors(t *testing.T) {
+	err := xerrs.NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeValidationError, "invalid user").
+		WithFieldError("email", xerrs.CodeRequiredField, "email is required").
+		WithFieldError("address.zip", "", "zip must have 5 digits")
+
+	doc := Render(err)
+	require.Len(t, doc.Errors, 2)
+	assert.Equal(t, Error{
+		Status: "400",
+		Code:   xerrs.CodeRequiredField,
+		Title:  "invalid user",
+		Detail: "email is required",
+		Source: &Source{Pointer: "/data/attributes/email"},
+		Meta:   map[string]any{MetaType: xerrs.ErrorTypeValidation},
+	}, doc.Errors[0])
+	assert.Equal(t, xerrs.CodeValidationError, doc.Errors[1].Code)
+	assert.Equal(t, "/data/attributes/address/zip", doc.Errors[1].Source.Pointer)
+}
+
+func TestRender_Empty(t *testing.T) {
+	data, err := json.Marshal(Render(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"errors": []}`, string(data))
+
+	doc := Render(errors.New("duplicate key value"))
+	require.Len(t, doc.Errors, 1)
+	assert.Equal(t, "409", doc.Errors[0].Status)
+	assert.Equal(t, xerrs.CodeResourceExists, doc.Errors[0].Code)
+}
+
+func TestPointer(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"email", "/data/attributes/email"},
+		{"items.0.quantity", "/data/attributes/items/0/quantity"},
+		{"a/b~c", "/data/attributes/a~1b~0c"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Pointer(tt.field))
+	}
+}
+
+func TestStatus(t *testing.T) {
+	notFound := xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "missing")
+	forbidden := xerrs.NewAppError(xerrs.ErrorTypeAuthorization, xerrs.CodeAccessDenied, "denied")
+	internal := xerrs.New("boom")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		expected int
+	}{
+		{"Single", []error{notFound}, http.StatusNotFound},
+		{"Same Status", []error{notFound, notFound}, http.StatusNotFound},
+		{"Client Errors", []error{notFound, forbidden}, http.StatusBadRequest},
+		{"Server Error", []error{notFound, internal}, http.StatusInternalServerError},
+		{"None", nil, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Status(tt.errs...))
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, xerrs.NewAppError(xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded, "slow down").
+		WithRetryAfter(2*time.Second))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	require.Len(t, doc.Errors, 1)
+	assert.Equal(t, "429", doc.Errors[0].Status)
+}
@@ -0,0 +1,187 @@
+package xerrs
+
+import (
+	"expvar"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// CodeOther replaces unregistered codes in observations to bound metric cardinality.
+const CodeOther = "OTHER"
+
+// TypeOther replaces error types that are neither built in nor registered with
+// RegisterErrorType in observations, to bound metric cardinality.
+const TypeOther ErrorType = "OTHER"
+
+// Observation describes a single observed error.
+type Observation struct {
+	Type       ErrorType
+	Code       string
+	Route      string
+	HTTPStatus int
+}
+
+// Recorder receives observed errors, typically to update metrics.
+type Recorder interface {
+	Record(obs Observation)
+}
+
+// RecorderFunc adapts a function to the Recorder interface.
+type RecorderFunc func(obs Observation)
+
+// Record calls f(obs).
+func (f RecorderFunc) Record(obs Observation) {
+	f(obs)
+}
+
+// multiRecorder forwards observations to several recorders.
+type multiRecorder []Recorder
+
+// Record forwards obs to every recorder.
+func (m multiRecorder) Record(obs Observation) {
+	for _, r := range m {
+		r.Record(obs)
+	}
+}
+
+// MultiRecorder returns a Recorder that forwards observations to every given recorder.
+func MultiRecorder(recorders ...Recorder) Recorder {
+	return multiRecorder(append([]Recorder(nil), recorders...))
+}
+
+// recorderHolder wraps the global recorder so it can be stored atomically.
+type recorderHolder struct {
+	recorder Recorder
+}
+
+// globalRecorder holds the recorder used by Observe.
+var globalRecorder atomic.Pointer[recorderHolder]
+
+// SetRecorder sets the recorder used by Observe and ObserveRoute. A nil
+// recorder disables observation.
+func SetRecorder(r Recorder) {
+	globalRecorder.Store(&recorderHolder{recorder: r})
+}
+
+// registeredCodes holds the codes reported as-is; other codes become CodeOther.
+var registeredCodes sync.Map
+
+func init() {
+	RegisterCodes(
+		CodeValidationError, CodeInvalidInput, CodeRequiredField, CodeInvalidFormat, CodeInvalidRange,
+		CodeInvalidCredentials, CodeTokenExpired, CodeTokenInvalid, CodeLoginRequired, CodeAuthRequired,
+		CodeAccessDenied, CodeInsufficientPermissions, CodeResourceForbidden, CodeInsufficientRole,
+		CodeResourceNotFound, CodeResourceExists,
+		CodeMethodNotAllowed, CodeRequestTimeout, CodeResourceGone, CodePreconditionFailed,
+		CodePayloadTooLarge, CodeUnsupportedMediaType, CodeUnprocessableEntity, CodeClientClosedRequest,
+		CodeRateLimitExceeded,
+		CodeInternalError, CodeDatabaseError, CodeDatabaseConnection, CodeDatabaseConstraint,
+		CodeInternalTimeout, CodeConfigurationError, CodeOperationCanceled,
+		CodeDatabaseDeadlock, CodeDatabaseSerialization,
+		CodeInvalidUserContext, CodeOrgContextMissing, CodeInvalidOrgContext, CodeUserRoleNotFound,
+		CodePermissionCheckFailed,
+		CodeExternalError, CodeExternalTimeout, CodeExternalUnavailable,
+		CodeServiceUnavailable, CodeNotImplemented, CodeGatewayTimeout,
+	)
+}
+
+// RegisterCodes registers application-specific codes so that observations
+// report them instead of CodeOther. The built-in codes are registered by default.
+func RegisterCodes(codes ...string) {
+	for _, code := range codes {
+		registeredCodes.Store(code, struct{}{})
+	}
+}
+
+// isRegisteredCode reports whether code was registered with RegisterCodes.
+func isRegisteredCode(code string) bool {
+	_, ok := registeredCodes.Load(code)
+	return ok
+}
+
+// Observe reports err to the recorder set with SetRecorder.
+func Observe(err error) {
+	ObserveRoute(err, "")
+}
+
+// ObserveRoute reports err for the given route to the recorder set with SetRecorder.
+func ObserveRoute(err error, route string) {
+	holder := globalRecorder.Load()
+	if err == nil || holder == nil || holder.recorder == nil {
+		return
+	}
+	holder.recorder.Record(newObservation(err, route))
+}
+
+// newObservation builds the observation for err, collapsing unregistered types
+// and codes.
+func newObservation(err error, route string) Observation {
+	obs := Observation{Route: route}
+	if appErr, ok := AsAppError(err); ok {
+		obs.Type, obs.Code, obs.HTTPStatus = appErr.Type, appErr.Code, appErr.GetHTTPStatus()
+	} else {
+		obs.Type, obs.Code = detectErrorTypeAndCode(err)
+		obs.HTTPStatus = obs.Type.DefaultHTTPStatus()
+	}
+	if _, ok := LookupErrorType(obs.Type); !ok {
+		obs.Type = TypeOther
+	}
+	if !isRegisteredCode(obs.Code) {
+		obs.Code = CodeOther
+	}
+	return obs
+}
+
+// MetricsMiddleware observes the error reported with SetRequestError for each
+// request, using the ServeMux pattern that matched the request as the route.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithRequestError(r.Context()))
+		next.ServeHTTP(w, r)
+		if err := RequestError(r.Context()); err != nil {
+			ObserveRoute(err, r.Pattern)
+		}
+	})
+}
+
+// ExpvarRecorder counts observations in an expvar.Map nested by type, code
+// and route.
+type ExpvarRecorder struct {
+	mu     sync.Mutex
+	counts *expvar.Map
+}
+
+// NewExpvarRecorder creates an ExpvarRecorder. When name is not empty, the
+// counters are published with expvar under that name.
+func NewExpvarRecorder(name string) *ExpvarRecorder {
+	r := &ExpvarRecorder{counts: new(expvar.Map)}
+	if name != "" {
+		expvar.Publish(name, r.counts)
+	}
+	return r
+}
+
+// Record increments the counter for obs.
+func (r *ExpvarRecorder) Record(obs Observation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byCode := childMap(r.counts, string(obs.Type))
+	byRoute := childMap(byCode, obs.Code)
+	byRoute.Add(obs.Route, 1)
+}
+
+// Map returns the underlying counters.
+func (r *ExpvarRecorder) Map() *expvar.Map {
+	return r.counts
+}
+
+// childMap returns the nested map stored under key, creating it when missing.
+func childMap(parent *expvar.Map, key string) *expvar.Map {
+	if child, ok := parent.Get(key).(*expvar.Map); ok {
+		return child
+	}
+	child := new(expvar.Map)
+	parent.Set(key, child)
+	return child
+}
@@ -0,0 +1,103 @@
+package xerrs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureObservations installs a recorder collecting observations for the duration of a test.
+func captureObservations(t *testing.T) *[]Observation {
+	t.Helper()
+	var observed []Observation
+	SetRecorder(RecorderFunc(func(obs Observation) { observed = append(observed, obs) }))
+	t.Cleanup(func() { SetRecorder(nil) })
+	return &observed
+}
+
+func TestObserve(t *testing.T) {
+	observed := captureObservations(t)
+
+	Observe(New("user not found").AsResourceNotFound())
+	Observe(errors.New("connection refused"))
+	Observe(nil)
+
+	require.Len(t, *observed, 2)
+	assert.Equal(t, Observation{Type: ErrorTypeNotFound, Code: CodeResourceNotFound, HTTPStatus: 404}, (*observed)[0])
+	assert.Equal(t, Observation{Type: ErrorTypeExternal, Code: CodeExternalError, HTTPStatus: 502}, (*observed)[1])
+}
+
+func TestObserve_CollapsesUnregisteredCodes(t *testing.T) {
+	observed := captureObservations(t)
+
+	Observe(New("x").AsValidationWithCode("UNREGISTERED_CODE"))
+	RegisterCodes("REGISTERED_CODE")
+	Observe(New("x").AsValidationWithCode("REGISTERED_CODE"))
+
+	require.Len(t, *observed, 2)
+	assert.Equal(t, CodeOther, (*observed)[0].Code)
+	assert.Equal(t, "REGISTERED_CODE", (*observed)[1].Code)
+}
+
+func TestObserve_CollapsesUnregisteredTypes(t *testing.T) {
+	observed := captureObservations(t)
+	const registered ErrorType = "QUOTA"
+	withRegisteredErrorType(t, registered, ErrorTypeOptions{HTTPStatus: http.StatusForbidden})
+
+	Observe(New("x").WithType("tenant-42"))
+	Observe(New("x").WithType(registered))
+
+	require.Len(t, *observed, 2)
+	assert.Equal(t, TypeOther, (*observed)[0].Type)
+	assert.Equal(t, registered, (*observed)[1].Type)
+}
+
+func TestObserve_WithoutRecorder(t *testing.T) {
+	SetRecorder(nil)
+	assert.NotPanics(t, func() { Observe(New("boom")) })
+}
+
+func TestMultiRecorder(t *testing.T) {
+	var first, second int
+	recorder := MultiRecorder(
+		RecorderFunc(func(Observation) { first++ }),
+		RecorderFunc(func(Observation) { second++ }),
+	)
+	recorder.Record(Observation{})
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 1, second)
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	observed := captureObservations(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		SetRequestError(r.Context(), New("user not found").AsResourceNotFound())
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {})
+	handler := MetricsMiddleware(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	require.Len(t, *observed, 1)
+	assert.Equal(t, "GET /users/{id}", (*observed)[0].Route)
+	assert.Equal(t, CodeResourceNotFound, (*observed)[0].Code)
+}
+
+func TestExpvarRecorder(t *testing.T) {
+	recorder := NewExpvarRecorder("")
+	recorder.Record(Observation{Type: ErrorTypeNotFound, Code: CodeResourceNotFound, Route: "GET /users/{id}"})
+	recorder.Record(Observation{Type: ErrorTypeNotFound, Code: CodeResourceNotFound, Route: "GET /users/{id}"})
+	recorder.Record(Observation{Type: ErrorTypeInternal, Code: CodeOther})
+
+	assert.JSONEq(t,
+		`{"INTERNAL": {"OTHER": {"": 1}}, "NOT_FOUND": {"RESOURCE_NOT_FOUND": {"GET /users/{id}": 2}}}`,
+		recorder.Map().String())
+}
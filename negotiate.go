@@ -0,0 +1,339 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types offered by Renderer.
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeText = "text/plain"
+	MediaTypeHTML = "text/html"
+)
+
+// DefaultFormats lists the media types offered by a Renderer by default, in
+// order of preference.
+var DefaultFormats = []string{MediaTypeProblemJSON, MediaTypeJSON, MediaTypeHTML, MediaTypeText}
+
+// EncodeFunc writes err in one media type.
+type EncodeFunc func(w io.Writer, r *http.Request, err *AppError) error
+
+// ErrorPage is the data passed to HTML error templates.
+type ErrorPage struct {
+	Error      *AppError
+	Status     int
+	StatusText string
+	// Message is the error message, replaced by the status text for server
+	// errors outside debug mode.
+	Message string
+	Path    string
+	// Debug is set in debug mode, where Stack holds the stack trace.
+	Debug bool
+	Stack []Frame
+}
+
+// RendererOptions configures a Renderer.
+type RendererOptions struct {
+	// Formats lists the media types offered, in order of preference when
+	// the Accept header allows several. Defaults to DefaultFormats.
+	Formats []string
+	// Encoders adds encoders for other media types, or replaces the
+	// built-in ones.
+	Encoders map[string]EncodeFunc
+	// Routes overrides Formats for requests matching a ServeMux pattern,
+	// such as "GET /api/".
+	Routes map[string][]string
+	// Template renders HTML errors without a type or status template.
+	Template *template.Template
+	// TypeTemplates renders HTML errors by type. They take precedence over
+	// StatusTemplates.
+	TypeTemplates map[ErrorType]*template.Template
+	// StatusTemplates renders HTML errors by HTTP status.
+	StatusTemplates map[int]*template.Template
+}
+
+// Renderer writes errors in the format negotiated from the Accept header:
+// problem details, the JSON error response schema, HTML or plain text. In debug
+// mode, HTML errors are rendered as a development page with the stack trace.
+type Renderer struct {
+	opts     RendererOptions
+	encoders map[string]EncodeFunc
+}
+
+// DefaultRenderer is the Renderer used by RenderError.
+var DefaultRenderer = NewRenderer(nil)
+
+// NewRenderer returns a Renderer. A nil opts uses the defaults.
+func NewRenderer(opts *RendererOptions) *Renderer {
+	r := &Renderer{}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if len(r.opts.Formats) == 0 {
+		r.opts.Formats = DefaultFormats
+	}
+	if r.opts.Template == nil {
+		r.opts.Template = defaultErrorTemplate
+	}
+	r.encoders = map[string]EncodeFunc{
+		MediaTypeProblemJSON: encodeProblem,
+		MediaTypeJSON:        encodeJSON,
+		MediaTypeHTML:        r.encodeHTML,
+		MediaTypeText:        encodeText,
+	}
+	for mediaType, encode := range r.opts.Encoders {
+		r.encoders[mediaType] = encode
+	}
+	return r
+}
+
+// RenderError writes err with DefaultRenderer.
+func RenderError(w http.ResponseWriter, r *http.Request, err error) {
+	DefaultRenderer.Render(w, r, err)
+}
+
+// Render writes err with its HTTP status in the negotiated format. Errors
+// that are not AppErrors are classified as by Wrap, except that
+// context.Canceled is a client closed request error when the request context
+// was canceled.
+func (rd *Renderer) Render(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *AppError
+	if clientClosed(r.Context(), err) {
+		appErr = clientClosedError(err)
+	} else {
+		appErr = ToAppError(err)
+	}
+	mediaType := rd.Negotiate(r)
+
+	h := w.Header()
+	h.Add("Vary", "Accept")
+	h.Set("Content-Type", contentType(mediaType))
+	if mediaType == MediaTypeJSON {
+		h.Add("Vary", ErrorSchemaHeader)
+		h.Set(ErrorSchemaHeader, strconv.Itoa(NegotiateErrorSchema(r)))
+	}
+	h.Set("X-Content-Type-Options", "nosniff")
+	SetRetryAfterHeader(h, appErr)
+	w.WriteHeader(appErr.GetHTTPStatus())
+	_ = rd.encoders[mediaType](w, r, appErr)
+}
+
+// Negotiate returns the media type used for errors of r: the offered format
+// with the highest quality in the Accept header, ties going to the first
+// offered. The first offered format is used when none is acceptable.
+func (rd *Renderer) Negotiate(r *http.Request) string {
+	offers := rd.offers(r)
+	accept := parseAccept(r.Header.Get("Accept"))
+	if len(accept) == 0 {
+		return offers[0]
+	}
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// offers returns the media types offered for r that have an encoder.
+func (rd *Renderer) offers(r *http.Request) []string {
+	formats := rd.opts.Formats
+	if route, ok := rd.opts.Routes[r.Pattern]; ok && r.Pattern != "" {
+		formats = route
+	}
+	offers := make([]string, 0, len(formats))
+	for _, mediaType := range formats {
+		if _, ok := rd.encoders[mediaType]; ok {
+			offers = append(offers, mediaType)
+		}
+	}
+	if len(offers) == 0 {
+		return []string{MediaTypeJSON}
+	}
+	return offers
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	params    map[string]string
+	q         float64
+}
+
+// parseAccept parses an Accept header, skipping invalid media ranges.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, params: params, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of mediaType under the most specific
+// matching range, or zero.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch r.mediaType {
+		case mediaType:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// contentType returns the Content-Type header for a media type.
+func contentType(mediaType string) string {
+	if strings.HasPrefix(mediaType, "text/") {
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
+
+// publicMessage returns the message shown to end users: the status text for
+// server errors outside debug mode, the error message otherwise.
+func publicMessage(appErr *AppError) string {
+	if appErr.IsServerError() && !DebugMode() {
+		return http.StatusText(appErr.GetHTTPStatus())
+	}
+	return appErr.Message
+}
+
+// encodeProblem writes err as problem details.
+func encodeProblem(w io.Writer, r *http.Request, err *AppError) error {
+	problem := NewProblemDetails(err)
+	if r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+	return json.NewEncoder(w).Encode(problem)
+}
+
+// encodeJSON writes err in the JSON error response schema selected by r.
+func encodeJSON(w io.Writer, r *http.Request, err *AppError) error {
+	data, encodeErr := EncodeErrorResponse(err, NegotiateErrorSchema(r))
+	if encodeErr != nil {
+		return encodeErr
+	}
+	_, encodeErr = w.Write(append(data, '\n'))
+	return encodeErr
+}
+
+// encodeText writes the public message of err.
+func encodeText(w io.Writer, _ *http.Request, err *AppError) error {
+	_, writeErr := io.WriteString(w, publicMessage(err)+"\n")
+	return writeErr
+}
+
+// encodeHTML executes the template for err, or the development page in
+// debug mode.
+func (rd *Renderer) encodeHTML(w io.Writer, r *http.Request, err *AppError) error {
+	status := err.GetHTTPStatus()
+	page := ErrorPage{
+		Error:      err,
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    publicMessage(err),
+	}
+	if r.URL != nil {
+		page.Path = r.URL.Path
+	}
+	if DebugMode() {
+		page.Debug = true
+		page.Stack = err.StackFramesWithOptions(StackFrameOptions{TrimGOROOT: true, SkipRuntime: true})
+		return devErrorTemplate.Execute(w, page)
+	}
+	return rd.template(err).Execute(w, page)
+}
+
+// template returns the HTML template for err.
+func (rd *Renderer) template(err *AppError) *template.Template {
+	if tmpl, ok := rd.opts.TypeTemplates[err.Type]; ok {
+		return tmpl
+	}
+	if tmpl, ok := rd.opts.StatusTemplates[err.GetHTTPStatus()]; ok {
+		return tmpl
+	}
+	return rd.opts.Template
+}
+
+// defaultErrorTemplate is the HTML page of errors without a configured template.
+var defaultErrorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Status}} {{.StatusText}}</title></head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+
+// devErrorTemplate is the HTML page of errors in debug mode.
+var devErrorTemplate = template.Must(template.New("dev").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Status}} {{.Error.Message}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { text-align: left; padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+pre { background: #f4f4f4; padding: 1em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Error.Message}}</p>
+<table>
+<tr><th>Type</th><td>{{.Error.Type}}</td></tr>
+<tr><th>Code</th><td>{{.Error.Code}}</td></tr>
+{{- with .Error.Details}}
+<tr><th>Details</th><td>{{.}}</td></tr>
+{{- end}}
+{{- with .Path}}
+<tr><th>Path</th><td>{{.}}</td></tr>
+{{- end}}
+</table>
+{{- with .Error.FieldErrors}}
+<h2>Fields</h2>
+<table>
+{{- range .}}
+<tr><th>{{.Field}}</th><td>{{.Code}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Stack}}
+<h2>Stack trace</h2>
+<pre>
+{{- range .}}
+{{.Function}}
+	{{.File}}:{{.Line}}
+{{- end}}
+</pre>
+{{- end}}
+</body>
+</html>
+`))
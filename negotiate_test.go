@@ -0,0 +1,227 @@
+package xerrs
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAcceptRequest(accept string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	return r
+}
+
+func TestRenderer_Negotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{"No Accept", "", MediaTypeProblemJSON},
+		{"Any", "*/*", MediaTypeProblemJSON},
+		{"JSON", "application/json", MediaTypeJSON},
+		{"Problem", "application/problem+json", MediaTypeProblemJSON},
+		{"Browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", MediaTypeHTML},
+		{"Text", "text/plain", MediaTypeText},
+		{"Text Wildcard", "text/*", MediaTypeHTML},
+		{"Quality", "application/json;q=0.5, text/plain", MediaTypeText},
+		{"Excluded", "text/*;q=0, */*", MediaTypeProblemJSON},
+		{"Unsupported", "image/png", MediaTypeProblemJSON},
+		{"Invalid", "not a media type", MediaTypeProblemJSON},
+	}
+	renderer := NewRenderer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderer.Negotiate(newAcceptRequest(tt.accept)))
+		})
+	}
+}
+
+func TestRenderer_Formats(t *testing.T) {
+	err := NewAppError(ErrorTypeNotFound, CodeResourceNotFound, "user not found")
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"application/problem+json", MediaTypeProblemJSON, `{"type":"about:blank","title":"user not found","status":404,"instance":"/users/42","code":"RESOURCE_NOT_FOUND"}`},
+		{"application/json", MediaTypeJSON, `"type":"NOT_FOUND","code":"RESOURCE_NOT_FOUND","message":"user not found"`},
+		{"text/plain", "text/plain; charset=utf-8", "user not found\n"},
+		{"text/html", "text/html; charset=utf-8", "<h1>404 Not Found</h1>\n<p>user not found</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewRenderer(nil).Render(rec, newAcceptRequest(tt.accept), err)
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			assert.Contains(t, rec.Body.String(), tt.body)
+		})
+	}
+}
+
+func TestRenderer_RetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RenderError(rec, newAcceptRequest(""), New("slow down").AsTooManyRequests().WithRetryAfter(3*time.Second))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+}
+
+func TestRenderer_ClientClosed(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected int
+	}{
+		{"Request Canceled", canceled, context.Canceled, StatusClientClosedRequest},
+		{"Wrapped Request Canceled", canceled, Wrap(context.Canceled, "query users"), StatusClientClosedRequest},
+		{"Operation Canceled Code", canceled, New("stopped").WithCode(CodeOperationCanceled), StatusClientClosedRequest},
+		{"Other Error", canceled, New("boom"), http.StatusInternalServerError},
+		{"Request Live", context.Background(), context.Canceled, http.StatusInternalServerError},
+		{"Wrapped Request Live", context.Background(), Wrap(context.Canceled, "query users"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RenderError(rec, newAcceptRequest("").WithContext(tt.ctx), tt.err)
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestRenderer_MasksServerErrors(t *testing.T) {
+	withDebugMode(t, false)
+	err := New("connection to 10.0.0.5 refused")
+
+	for _, accept := range []string{"text/plain", "text/html"} {
+		rec := httptest.NewRecorder()
+		NewRenderer(nil).Render(rec, newAcceptRequest(accept), err)
+		assert.Contains(t, rec.Body.String(), "Internal Server Error")
+		assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+	}
+}
+
+func TestRenderer_Templates(t *testing.T) {
+	withDebugMode(t, false)
+	renderer := NewRenderer(&RendererOptions{
+		TypeTemplates: map[ErrorType]*template.Template{
+			ErrorTypeAuthentication: template.Must(template.New("login").Parse(`please log in: {{.Message}}`)),
+		},
+		StatusTemplates: map[int]*template.Template{
+			http.StatusNotFound: template.Must(template.New("404").Parse(`nothing at {{.Path}}`)),
+		},
+		Template: template.Must(template.New("default").Parse(`error {{.Status}}`)),
+	})
+
+	tests := []struct {
+		name     string
+		err      *AppError
+		expected string
+	}{
+		{"Type", NewAppError(ErrorTypeAuthentication, CodeAuthRequired, "session expired"), "please log in: session expired"},
+		{"Status", NewAppError(ErrorTypeGone, CodeResourceGone, "gone").WithHTTPStatus(http.StatusNotFound), "nothing at /users/42"},
+		{"Default", NewAppError(ErrorTypeConflict, CodeResourceExists, "exists"), "error 409"},
+		{"Escaped", NewAppError(ErrorTypeAuthentication, CodeAuthRequired, "<script>"), "please log in: &lt;script&gt;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			renderer.Render(rec, newAcceptRequest("text/html"), tt.err)
+			assert.Equal(t, tt.expected, rec.Body.String())
+		})
+	}
+}
+
+func TestRenderer_DevPage(t *testing.T) {
+	withDebugMode(t, true)
+	renderer := NewRenderer(&RendererOptions{
+		Template: template.Must(template.New("default").Parse(`production page`)),
+	})
+
+	rec := httptest.NewRecorder()
+	renderer.Render(rec, newAcceptRequest("text/html"), New("connection refused").
+		WithDetails("dial tcp 10.0.0.5:5432").
+		WithFieldError("dsn", "", "invalid host"))
+
+	body := rec.Body.String()
+	assert.NotContains(t, body, "production page")
+	assert.Contains(t, body, "connection refused")
+	assert.Contains(t, body, "dial tcp 10.0.0.5:5432")
+	assert.Contains(t, body, "invalid host")
+	assert.Contains(t, body, "Stack trace")
+	assert.Contains(t, body, "TestRenderer_DevPage")
+}
+
+func TestRenderer_Routes(t *testing.T) {
+	renderer := NewRenderer(&RendererOptions{
+		Routes: map[string][]string{
+			"GET /api/": {MediaTypeJSON},
+		},
+	})
+	mux := http.NewServeMux()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		renderer.Render(w, r, NewAppError(ErrorTypeNotFound, CodeResourceNotFound, "not found"))
+	}
+	mux.HandleFunc("GET /api/", handler)
+	mux.HandleFunc("GET /", handler)
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/api/users", MediaTypeJSON},
+		{"/users", "text/html; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set("Accept", "text/html")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestRenderer_CustomEncoder(t *testing.T) {
+	const mediaType = "application/xml"
+	renderer := NewRenderer(&RendererOptions{
+		Formats: []string{MediaTypeJSON, mediaType},
+		Encoders: map[string]EncodeFunc{
+			mediaType: func(w io.Writer, _ *http.Request, err *AppError) error {
+				_, writeErr := io.WriteString(w, "<error>"+err.Code+"</error>")
+				return writeErr
+			},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	renderer.Render(rec, newAcceptRequest("application/xml"), New("boom"))
+	assert.Equal(t, "<error>INTERNAL_ERROR</error>", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	renderer.Render(rec, newAcceptRequest("text/html"), New("boom"))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, CodeInternalError, decoded["code"])
+}
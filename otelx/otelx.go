@@ -0,0 +1,66 @@
+// Package otelx records xerrs errors on OpenTelemetry spans.
+package otelx
+
+import (
+	"net/http"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// CodeKey is the span attribute holding the AppError code.
+const CodeKey = attribute.Key("xerrs.code")
+
+// RecordError records err on span. It adds an "exception" event with the
+// error stack trace and sets the error.type, xerrs.code and
+// http.response.status_code attributes. The span status is set to Error only
+// for server errors, as told by AppError.IsServerError; errors that are not
+// AppErrors count as server errors.
+func RecordError(span trace.Span, err error) {
+	if err == nil || span == nil || !span.IsRecording() {
+		return
+	}
+	appErr, ok := xerrs.AsAppError(err)
+	if !ok {
+		span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+			semconv.ExceptionType(reflect.TypeOf(err).String()),
+			semconv.ExceptionMessage(err.Error()),
+		))
+		span.SetAttributes(semconv.ErrorType(err))
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	status := appErr.GetHTTPStatus()
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+		semconv.ExceptionType(string(appErr.Type)),
+		semconv.ExceptionMessage(appErr.Error()),
+		semconv.ExceptionStacktrace(appErr.GetStackTrace()),
+	))
+	span.SetAttributes(
+		semconv.ErrorTypeKey.String(string(appErr.Type)),
+		CodeKey.String(appErr.Code),
+		semconv.HTTPResponseStatusCode(status),
+	)
+	if appErr.IsServerError() {
+		span.SetStatus(codes.Error, appErr.Message)
+	}
+}
+
+// Middleware records the error reported with xerrs.SetRequestError on the
+// span found in the request context. It is meant to be installed inside the
+// middleware that starts the server span, such as otelhttp.NewHandler.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := xerrs.WithRequestError(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+		if err := xerrs.RequestError(ctx); err != nil {
+			RecordError(trace.SpanFromContext(ctx), err)
+		}
+	})
+}
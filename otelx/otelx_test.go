@@ -0,0 +1,125 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// newTestTracer returns a tracer whose ended spans are collected by the returned exporter.
+func newTestTracer(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider, exporter
+}
+
+// attributeMap indexes span or event attributes by key.
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestRecordError_ServerError(t *testing.T) {
+	provider, exporter := newTestTracer(t)
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, xerrs.New("db down").AsDatabaseConnection())
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	stub := spans[0]
+	assert.Equal(t, codes.Error, stub.Status.Code)
+	assert.Equal(t, "db down", stub.Status.Description)
+
+	attrs := attributeMap(stub.Attributes)
+	assert.Equal(t, string(xerrs.ErrorTypeInternal), attrs[semconv.ErrorTypeKey].AsString())
+	assert.Equal(t, xerrs.CodeDatabaseConnection, attrs[CodeKey].AsString())
+	assert.Equal(t, int64(500), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+
+	require.Len(t, stub.Events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, stub.Events[0].Name)
+	eventAttrs := attributeMap(stub.Events[0].Attributes)
+	assert.Equal(t, "[INTERNAL] DATABASE_CONNECTION: db down", eventAttrs[semconv.ExceptionMessageKey].AsString())
+	assert.Contains(t, eventAttrs[semconv.ExceptionStacktraceKey].AsString(), "TestRecordError_ServerError")
+}
+
+func TestRecordError_ClientError(t *testing.T) {
+	provider, exporter := newTestTracer(t)
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, xerrs.New("user not found").AsResourceNotFound())
+	span.End()
+
+	stub := exporter.GetSpans()[0]
+	assert.Equal(t, codes.Unset, stub.Status.Code)
+	assert.Equal(t, int64(404), attributeMap(stub.Attributes)[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	assert.Len(t, stub.Events, 1)
+}
+
+func TestRecordError_RegisteredClass(t *testing.T) {
+	const dependencyDown xerrs.ErrorType = "OTELX_DEPENDENCY_DOWN"
+	xerrs.MustRegisterErrorType(dependencyDown, xerrs.ErrorTypeOptions{HTTPStatus: http.StatusFailedDependency, Class: xerrs.ClassServer})
+	provider, exporter := newTestTracer(t)
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, xerrs.NewAppError(dependencyDown, "DEPENDENCY", "dependency down"))
+	span.End()
+
+	stub := exporter.GetSpans()[0]
+	assert.Equal(t, codes.Error, stub.Status.Code)
+	assert.Equal(t, int64(424), attributeMap(stub.Attributes)[semconv.HTTPResponseStatusCodeKey].AsInt64())
+}
+
+func TestRecordError_PlainError(t *testing.T) {
+	provider, exporter := newTestTracer(t)
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, errors.New("plain"))
+	span.End()
+
+	stub := exporter.GetSpans()[0]
+	assert.Equal(t, codes.Error, stub.Status.Code)
+	assert.NotContains(t, attributeMap(stub.Attributes), CodeKey)
+}
+
+func TestRecordError_NilError(t *testing.T) {
+	provider, exporter := newTestTracer(t)
+	_, span := provider.Tracer("test").Start(context.Background(), "op")
+	RecordError(span, nil)
+	span.End()
+
+	stub := exporter.GetSpans()[0]
+	assert.Equal(t, codes.Unset, stub.Status.Code)
+	assert.Empty(t, stub.Events)
+}
+
+func TestMiddleware(t *testing.T) {
+	provider, exporter := newTestTracer(t)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xerrs.SetRequestError(r.Context(), xerrs.New("upstream down").AsServiceUnavailable())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "GET /users")
+	req := httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	span.End()
+
+	stub := exporter.GetSpans()[0]
+	assert.Equal(t, codes.Error, stub.Status.Code)
+	assert.Equal(t, xerrs.CodeServiceUnavailable, attributeMap(stub.Attributes)[CodeKey].AsString())
+}
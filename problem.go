@@ -0,0 +1,31 @@
+package xerrs
+
+// MediaTypeProblemJSON is the media type of RFC 9457 problem details.
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 9457 problem details object. Code and Fields are
+// extension members.
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Fields   []FieldError `json:"fields,omitempty"`
+}
+
+// NewProblemDetails returns the problem details of err. The title is the
+// error message and the detail its Details. Errors that are not AppErrors are
+// classified as by Wrap.
+func NewProblemDetails(err error) ProblemDetails {
+	appErr := ToAppError(err)
+	return ProblemDetails{
+		Type:   "about:blank",
+		Title:  appErr.Message,
+		Status: appErr.GetHTTPStatus(),
+		Detail: appErr.Details,
+		Code:   appErr.Code,
+		Fields: appErr.FieldErrors(),
+	}
+}
@@ -0,0 +1,38 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProblemDetails(t *testing.T) {
+	err := NewAppError(ErrorTypeValidation, CodeValidationError, "invalid user").
+		WithDetails("check the request body").
+		WithFieldError("email", CodeRequiredField, "email is required")
+
+	data, marshalErr := json.Marshal(NewProblemDetails(err))
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "invalid user",
+		"status": 400,
+		"detail": "check the request body",
+		"code": "VALIDATION_ERROR",
+		"fields": [{"field": "email", "code": "REQUIRED_FIELD", "message": "email is required"}]
+	}`, string(data))
+}
+
+func TestNewProblemDetails_PlainError(t *testing.T) {
+	problem := NewProblemDetails(errors.New("duplicate key value"))
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, CodeResourceExists, problem.Code)
+	assert.Equal(t, "duplicate key value", problem.Title)
+
+	problem = NewProblemDetails(nil)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+}
@@ -0,0 +1,64 @@
+// Package promx exports xerrs error observations as Prometheus metrics.
+package promx
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// Label names of the errors counter.
+const (
+	LabelType  = "type"
+	LabelCode  = "code"
+	LabelRoute = "route"
+)
+
+// Options configures NewCollector.
+type Options struct {
+	// Namespace and Subsystem prefix the metric name.
+	Namespace string
+	Subsystem string
+	// Name of the counter. Defaults to "errors_total".
+	Name string
+}
+
+// Collector is a prometheus.Collector and xerrs.Recorder that counts errors
+// by type, code and route.
+type Collector struct {
+	counter *prometheus.CounterVec
+}
+
+// NewCollector creates a Collector. A nil opts uses the defaults.
+func NewCollector(opts *Options) *Collector {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Name == "" {
+		o.Name = "errors_total"
+	}
+	return &Collector{
+		counter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.Namespace,
+			Subsystem: o.Subsystem,
+			Name:      o.Name,
+			Help:      "Number of observed application errors by type, code and route.",
+		}, []string{LabelType, LabelCode, LabelRoute}),
+	}
+}
+
+// Record increments the counter for obs.
+func (c *Collector) Record(obs xerrs.Observation) {
+	c.counter.WithLabelValues(string(obs.Type), obs.Code, obs.Route).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.counter.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.counter.Collect(ch)
+}
@@ -0,0 +1,50 @@
+package promx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(&Options{Namespace: "app"})
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	collector.Record(xerrs.Observation{Type: xerrs.ErrorTypeNotFound, Code: xerrs.CodeResourceNotFound, Route: "GET /users/{id}"})
+	collector.Record(xerrs.Observation{Type: xerrs.ErrorTypeNotFound, Code: xerrs.CodeResourceNotFound, Route: "GET /users/{id}"})
+	collector.Record(xerrs.Observation{Type: xerrs.ErrorTypeInternal, Code: xerrs.CodeOther})
+
+	expected := `
+# HELP app_errors_total Number of observed application errors by type, code and route.
+# TYPE app_errors_total counter
+app_errors_total{code="OTHER",route="",type="INTERNAL"} 1
+app_errors_total{code="RESOURCE_NOT_FOUND",route="GET /users/{id}",type="NOT_FOUND"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "app_errors_total"))
+}
+
+func TestCollector_WithMiddleware(t *testing.T) {
+	collector := NewCollector(nil)
+	xerrs.SetRecorder(collector)
+	t.Cleanup(func() { xerrs.SetRecorder(nil) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		xerrs.SetRequestError(r.Context(), xerrs.New("order missing").AsNotFoundWithCode("ORDER_MISSING"))
+		w.WriteHeader(http.StatusNotFound)
+	})
+	handler := xerrs.MetricsMiddleware(mux)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	count := testutil.ToFloat64(collector.counter.WithLabelValues(string(xerrs.ErrorTypeNotFound), xerrs.CodeOther, "GET /orders/{id}"))
+	assert.Equal(t, float64(1), count)
+}
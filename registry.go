@@ -0,0 +1,250 @@
+package xerrs
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Severity describes how serious errors of a type are, for logging and reporting.
+type Severity string
+
+// Severities, from least to most serious.
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+	SeverityFatal   Severity = "fatal"
+)
+
+// ErrorClass tells whether errors of a type are caused by the client or the server.
+type ErrorClass int
+
+// Error classes.
+const (
+	// ClassAuto derives the class from the HTTP status: 5xx statuses are
+	// server errors and other statuses are client errors.
+	ClassAuto ErrorClass = iota
+	ClassClient
+	ClassServer
+)
+
+// gRPC status codes, as defined by google.golang.org/grpc/codes.
+const (
+	grpcCanceled           uint32 = 1
+	grpcUnknown            uint32 = 2
+	grpcInvalidArgument    uint32 = 3
+	grpcDeadlineExceeded   uint32 = 4
+	grpcNotFound           uint32 = 5
+	grpcAlreadyExists      uint32 = 6
+	grpcPermissionDenied   uint32 = 7
+	grpcResourceExhausted  uint32 = 8
+	grpcFailedPrecondition uint32 = 9
+	grpcAborted            uint32 = 10
+	grpcOutOfRange         uint32 = 11
+	grpcUnimplemented      uint32 = 12
+	grpcInternal           uint32 = 13
+	grpcUnavailable        uint32 = 14
+	grpcDataLoss           uint32 = 15
+	grpcUnauthenticated    uint32 = 16
+)
+
+// ErrorTypeOptions describes a registered error type.
+type ErrorTypeOptions struct {
+	// HTTPStatus is the default HTTP status. Zero means 500.
+	HTTPStatus int
+	// GRPCCode is the google.golang.org/grpc/codes.Code value. Zero derives
+	// it from the HTTP status.
+	GRPCCode uint32
+	// Severity is used for log levels and error reports. Empty derives it
+	// from the class: errors for server errors, warnings otherwise.
+	Severity Severity
+	// Retryable marks errors of the type as retryable by default.
+	Retryable bool
+	// Class tells whether errors of the type are client or server errors.
+	Class ErrorClass
+}
+
+// builtinErrorTypes holds the options of the built-in error types.
+var builtinErrorTypes = map[ErrorType]ErrorTypeOptions{
+	ErrorTypeValidation:           {HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	ErrorTypeAuthentication:       {HTTPStatus: http.StatusUnauthorized, GRPCCode: grpcUnauthenticated},
+	ErrorTypeAuthorization:        {HTTPStatus: http.StatusForbidden, GRPCCode: grpcPermissionDenied},
+	ErrorTypeNotFound:             {HTTPStatus: http.StatusNotFound, GRPCCode: grpcNotFound},
+	ErrorTypeConflict:             {HTTPStatus: http.StatusConflict, GRPCCode: grpcAlreadyExists},
+	ErrorTypeRateLimit:            {HTTPStatus: http.StatusTooManyRequests, GRPCCode: grpcResourceExhausted, Retryable: true},
+	ErrorTypeMethodNotAllowed:     {HTTPStatus: http.StatusMethodNotAllowed, GRPCCode: grpcUnimplemented},
+	ErrorTypeRequestTimeout:       {HTTPStatus: http.StatusRequestTimeout, GRPCCode: grpcDeadlineExceeded, Retryable: true},
+	ErrorTypeGone:                 {HTTPStatus: http.StatusGone, GRPCCode: grpcNotFound},
+	ErrorTypePreconditionFailed:   {HTTPStatus: http.StatusPreconditionFailed, GRPCCode: grpcFailedPrecondition},
+	ErrorTypePayloadTooLarge:      {HTTPStatus: http.StatusRequestEntityTooLarge, GRPCCode: grpcInvalidArgument},
+	ErrorTypeUnsupportedMediaType: {HTTPStatus: http.StatusUnsupportedMediaType, GRPCCode: grpcInvalidArgument},
+	ErrorTypeUnprocessable:        {HTTPStatus: http.StatusUnprocessableEntity, GRPCCode: grpcInvalidArgument},
+	ErrorTypeClientClosed:         {HTTPStatus: StatusClientClosedRequest, GRPCCode: grpcCanceled},
+	ErrorTypeInternal:             {HTTPStatus: http.StatusInternalServerError, GRPCCode: grpcInternal},
+	ErrorTypeNotImplemented:       {HTTPStatus: http.StatusNotImplemented, GRPCCode: grpcUnimplemented},
+	ErrorTypeExternal:             {HTTPStatus: http.StatusBadGateway, GRPCCode: grpcUnavailable},
+	ErrorTypeUnavailable:          {HTTPStatus: http.StatusServiceUnavailable, GRPCCode: grpcUnavailable, Retryable: true},
+	ErrorTypeGatewayTimeout:       {HTTPStatus: http.StatusGatewayTimeout, GRPCCode: grpcDeadlineExceeded, Retryable: true},
+}
+
+// errorTypes holds the registry once a type has been registered. It is
+// replaced, never modified, so that lookups need no locking.
+var (
+	errorTypes   atomic.Pointer[map[ErrorType]ErrorTypeOptions]
+	errorTypesMu sync.Mutex
+)
+
+// loadErrorTypes returns the current registry.
+func loadErrorTypes() map[ErrorType]ErrorTypeOptions {
+	if types := errorTypes.Load(); types != nil {
+		return *types
+	}
+	return builtinErrorTypes
+}
+
+// RegisterErrorType registers a custom error type, or overrides a built-in
+// one. Types are typically registered during initialization; lookups are
+// safe for concurrent use at any time. It returns an error if t is empty or
+// the HTTP status is invalid.
+func RegisterErrorType(t ErrorType, opts ErrorTypeOptions) error {
+	if t == "" {
+		return New("empty error type").AsInvalidInput()
+	}
+	if opts.HTTPStatus != 0 && (opts.HTTPStatus < 100 || opts.HTTPStatus > 599) {
+		return New(fmt.Sprintf("invalid HTTP status %d for error type %s", opts.HTTPStatus, t)).AsInvalidInput()
+	}
+
+	errorTypesMu.Lock()
+	defer errorTypesMu.Unlock()
+	current := loadErrorTypes()
+	types := make(map[ErrorType]ErrorTypeOptions, len(current)+1)
+	for existing, existingOpts := range current {
+		types[existing] = existingOpts
+	}
+	types[t] = opts
+	errorTypes.Store(&types)
+	return nil
+}
+
+// MustRegisterErrorType is like RegisterErrorType but panics on error.
+func MustRegisterErrorType(t ErrorType, opts ErrorTypeOptions) {
+	if err := RegisterErrorType(t, opts); err != nil {
+		panic(err)
+	}
+}
+
+// RegisteredErrorTypes returns the built-in and registered error types,
+// sorted.
+func RegisteredErrorTypes() []ErrorType {
+	return slices.Sorted(maps.Keys(loadErrorTypes()))
+}
+
+// LookupErrorType returns the options registered for t.
+func LookupErrorType(t ErrorType) (ErrorTypeOptions, bool) {
+	opts, ok := loadErrorTypes()[t]
+	return opts, ok
+}
+
+// GRPCCode returns the google.golang.org/grpc/codes.Code value for the type:
+// the registered one, or one derived from the HTTP status.
+func (et ErrorType) GRPCCode() uint32 {
+	if opts, ok := LookupErrorType(et); ok && opts.GRPCCode != 0 {
+		return opts.GRPCCode
+	}
+	return grpcCodeForStatus(et.DefaultHTTPStatus())
+}
+
+// IsServerError reports whether errors of the type are caused by the server.
+func (et ErrorType) IsServerError() bool {
+	if opts, ok := LookupErrorType(et); ok && opts.Class != ClassAuto {
+		return opts.Class == ClassServer
+	}
+	return et.DefaultHTTPStatus() >= http.StatusInternalServerError
+}
+
+// IsClientError reports whether errors of the type are caused by the client.
+func (et ErrorType) IsClientError() bool {
+	return !et.IsServerError()
+}
+
+// Severity returns the severity of errors of the type.
+func (et ErrorType) Severity() Severity {
+	if opts, ok := LookupErrorType(et); ok && opts.Severity != "" {
+		return opts.Severity
+	}
+	return severityForClass(et.IsServerError())
+}
+
+// IsServerError reports whether the error is caused by the server: the class
+// registered for its type, or one derived from its HTTP status.
+func (e *AppError) IsServerError() bool {
+	if e == nil {
+		return true
+	}
+	if opts, ok := LookupErrorType(e.Type); ok && opts.Class != ClassAuto {
+		return opts.Class == ClassServer
+	}
+	return e.GetHTTPStatus() >= http.StatusInternalServerError
+}
+
+// IsClientError reports whether the error is caused by the client.
+func (e *AppError) IsClientError() bool {
+	return !e.IsServerError()
+}
+
+// Severity returns the severity of the error: the one registered for its
+// type, or one derived from its class.
+func (e *AppError) Severity() Severity {
+	if e == nil {
+		return SeverityError
+	}
+	if opts, ok := LookupErrorType(e.Type); ok && opts.Severity != "" {
+		return opts.Severity
+	}
+	return severityForClass(e.IsServerError())
+}
+
+// severityForClass returns the default severity for server or client errors.
+func severityForClass(server bool) Severity {
+	if server {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+// grpcCodeForStatus returns the gRPC code matching an HTTP status.
+func grpcCodeForStatus(status int) uint32 {
+	switch status {
+	case http.StatusBadRequest:
+		return grpcInvalidArgument
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusNotFound:
+		return grpcNotFound
+	case http.StatusConflict:
+		return grpcAlreadyExists
+	case http.StatusTooManyRequests:
+		return grpcResourceExhausted
+	case StatusClientClosedRequest:
+		return grpcCanceled
+	case http.StatusNotImplemented:
+		return grpcUnimplemented
+	case http.StatusServiceUnavailable:
+		return grpcUnavailable
+	case http.StatusGatewayTimeout:
+		return grpcDeadlineExceeded
+	}
+	switch {
+	case status >= 400 && status < 500:
+		return grpcFailedPrecondition
+	case status >= 500:
+		return grpcInternal
+	}
+	return grpcUnknown
+}
@@ -0,0 +1,184 @@
+package xerrs
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withRegisteredErrorType(tb testing.TB, t ErrorType, opts ErrorTypeOptions) {
+	tb.Helper()
+	previous := errorTypes.Load()
+	require.NoError(tb, RegisterErrorType(t, opts))
+	tb.Cleanup(func() { errorTypes.Store(previous) })
+}
+
+func TestRegisterErrorType(t *testing.T) {
+	const paymentRequired ErrorType = "PAYMENT_REQUIRED"
+	assert.Equal(t, http.StatusInternalServerError, paymentRequired.DefaultHTTPStatus())
+
+	withRegisteredErrorType(t, paymentRequired, ErrorTypeOptions{
+		HTTPStatus: http.StatusPaymentRequired,
+		GRPCCode:   grpcFailedPrecondition,
+		Severity:   SeverityInfo,
+		Retryable:  true,
+	})
+
+	err := NewAppError(paymentRequired, "CARD_DECLINED", "card declined")
+	assert.Equal(t, http.StatusPaymentRequired, err.GetHTTPStatus())
+	assert.Equal(t, grpcFailedPrecondition, paymentRequired.GRPCCode())
+	assert.Equal(t, SeverityInfo, err.Severity())
+	assert.True(t, err.Retryable())
+	assert.True(t, paymentRequired.IsClientError())
+
+	opts, ok := LookupErrorType(paymentRequired)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusPaymentRequired, opts.HTTPStatus)
+}
+
+func TestRegisterErrorType_Derived(t *testing.T) {
+	const quotaExceeded ErrorType = "QUOTA_EXCEEDED"
+	withRegisteredErrorType(t, quotaExceeded, ErrorTypeOptions{HTTPStatus: http.StatusTooManyRequests})
+
+	assert.Equal(t, grpcResourceExhausted, quotaExceeded.GRPCCode())
+	assert.Equal(t, SeverityWarning, quotaExceeded.Severity())
+	assert.False(t, NewAppError(quotaExceeded, "QUOTA", "quota exceeded").Retryable())
+
+	const dependencyDown ErrorType = "DEPENDENCY_DOWN"
+	withRegisteredErrorType(t, dependencyDown, ErrorTypeOptions{HTTPStatus: http.StatusFailedDependency, Class: ClassServer})
+	assert.True(t, dependencyDown.IsServerError())
+	assert.Equal(t, SeverityError, dependencyDown.Severity())
+	assert.Equal(t, SeverityError, NewAppError(dependencyDown, "DEPENDENCY", "dependency down").Severity())
+	assert.Equal(t, grpcFailedPrecondition, dependencyDown.GRPCCode())
+}
+
+func TestAppError_IsServerError(t *testing.T) {
+	const dependencyDown ErrorType = "DEPENDENCY_DOWN"
+	withRegisteredErrorType(t, dependencyDown, ErrorTypeOptions{HTTPStatus: http.StatusFailedDependency, Class: ClassServer})
+
+	tests := []struct {
+		name     string
+		err      *AppError
+		server   bool
+		severity Severity
+	}{
+		{"Client Status", New("missing").AsResourceNotFound(), false, SeverityWarning},
+		{"Server Status", New("boom"), true, SeverityError},
+		{"Status Override", New("boom").WithHTTPStatus(http.StatusConflict), false, SeverityWarning},
+		{"Registered Server Class", NewAppError(dependencyDown, "DEPENDENCY", "dependency down"), true, SeverityError},
+		{"Nil", nil, true, SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.server, tt.err.IsServerError())
+			assert.Equal(t, !tt.server, tt.err.IsClientError())
+			assert.Equal(t, tt.severity, tt.err.Severity())
+		})
+	}
+}
+
+func TestRegisterErrorType_OverridesBuiltin(t *testing.T) {
+	withRegisteredErrorType(t, ErrorTypeValidation, ErrorTypeOptions{HTTPStatus: http.StatusUnprocessableEntity})
+	assert.Equal(t, http.StatusUnprocessableEntity, New("x").AsInvalidInput().GetHTTPStatus())
+}
+
+func TestRegisteredErrorTypes(t *testing.T) {
+	types := RegisteredErrorTypes()
+	assert.Len(t, types, len(builtinErrorTypes))
+	assert.True(t, slices.IsSorted(types))
+
+	withRegisteredErrorType(t, "PAYMENT_REQUIRED", ErrorTypeOptions{HTTPStatus: http.StatusPaymentRequired})
+	assert.Contains(t, RegisteredErrorTypes(), ErrorType("PAYMENT_REQUIRED"))
+}
+
+func TestRegisterErrorType_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		errorType ErrorType
+		opts      ErrorTypeOptions
+		message   string
+	}{
+		{"Empty Type", "", ErrorTypeOptions{}, "empty error type"},
+		{"Invalid Status", "BAD", ErrorTypeOptions{HTTPStatus: 42}, "invalid HTTP status 42 for error type BAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterErrorType(tt.errorType, tt.opts)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.message, ToAppError(err).Message)
+			}
+			_, ok := LookupErrorType(tt.errorType)
+			assert.False(t, ok)
+			assert.Panics(t, func() { MustRegisterErrorType(tt.errorType, tt.opts) })
+		})
+	}
+}
+
+func TestBuiltinErrorTypes(t *testing.T) {
+	tests := []struct {
+		errorType ErrorType
+		grpcCode  uint32
+		severity  Severity
+		server    bool
+	}{
+		{ErrorTypeValidation, grpcInvalidArgument, SeverityWarning, false},
+		{ErrorTypeAuthentication, grpcUnauthenticated, SeverityWarning, false},
+		{ErrorTypeNotFound, grpcNotFound, SeverityWarning, false},
+		{ErrorTypeRateLimit, grpcResourceExhausted, SeverityWarning, false},
+		{ErrorTypeClientClosed, grpcCanceled, SeverityWarning, false},
+		{ErrorTypeInternal, grpcInternal, SeverityError, true},
+		{ErrorTypeNotImplemented, grpcUnimplemented, SeverityError, true},
+		{ErrorTypeUnavailable, grpcUnavailable, SeverityError, true},
+		{ErrorTypeGatewayTimeout, grpcDeadlineExceeded, SeverityError, true},
+		{ErrorType("UNKNOWN"), grpcInternal, SeverityError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.errorType), func(t *testing.T) {
+			assert.Equal(t, tt.grpcCode, tt.errorType.GRPCCode())
+			assert.Equal(t, tt.severity, tt.errorType.Severity())
+			assert.Equal(t, tt.server, tt.errorType.IsServerError())
+		})
+	}
+}
+
+func TestRegisterErrorType_SlogLevel(t *testing.T) {
+	const notice ErrorType = "NOTICE"
+	withRegisteredErrorType(t, notice, ErrorTypeOptions{HTTPStatus: http.StatusInternalServerError, Severity: SeverityInfo})
+
+	var buf bytes.Buffer
+	next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	slog.New(NewSlogHandler(next, nil)).Debug("handled", "err", NewAppError(notice, "NOTICE", "heads up"))
+
+	assert.Equal(t, "INFO", decodeLogLine(t, &buf)["level"])
+}
+
+func TestRegisterErrorType_ConcurrentReads(t *testing.T) {
+	previous := errorTypes.Load()
+	t.Cleanup(func() { errorTypes.Store(previous) })
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_ = ErrorTypeValidation.DefaultHTTPStatus()
+				_ = ErrorType("CONCURRENT").Severity()
+			}
+		}()
+		if i%2 == 0 {
+			MustRegisterErrorType("CONCURRENT", ErrorTypeOptions{HTTPStatus: http.StatusTeapot})
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, http.StatusTeapot, ErrorType("CONCURRENT").DefaultHTTPStatus())
+}
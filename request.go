@@ -0,0 +1,81 @@
+package xerrs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// requestErrorKey is the context key of the request error slot.
+type requestErrorKey struct{}
+
+// requestErrorSlot holds the error reported for the request being served.
+type requestErrorSlot struct {
+	mu  sync.Mutex
+	err error
+}
+
+// WithRequestError returns a context that can hold the error of the request
+// being served. Middlewares install it before calling the next handler and
+// read the error back with RequestError. If ctx already carries a slot, it is
+// shared so that every middleware in the chain sees the same error.
+func WithRequestError(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(requestErrorKey{}).(*requestErrorSlot); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, requestErrorKey{}, &requestErrorSlot{})
+}
+
+// SetRequestError records err as the error of the request served with ctx.
+// It is a no-op when no middleware installed a slot with WithRequestError.
+// A context.Canceled error is recorded as a client closed request error when
+// ctx itself was canceled, that is when the client went away.
+func SetRequestError(ctx context.Context, err error) {
+	if slot, ok := ctx.Value(requestErrorKey{}).(*requestErrorSlot); ok {
+		if clientClosed(ctx, err) {
+			err = clientClosedError(err)
+		}
+		slot.mu.Lock()
+		slot.err = err
+		slot.mu.Unlock()
+	}
+}
+
+// RequestError returns the error recorded with SetRequestError, if any.
+func RequestError(ctx context.Context) error {
+	if slot, ok := ctx.Value(requestErrorKey{}).(*requestErrorSlot); ok {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		return slot.err
+	}
+	return nil
+}
+
+// clientClosed reports whether err is a cancellation caused by the client of
+// the request served with ctx: ctx was canceled and err is, or wraps, a
+// cancellation, including an AppError classified as CodeOperationCanceled.
+// Other cancellations, such as those of a context derived for a background
+// task, keep the classification of Wrap.
+func clientClosed(ctx context.Context, err error) bool {
+	if err == nil || !errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	if appErr, ok := AsAppError(err); ok {
+		if appErr.Type == ErrorTypeClientClosed {
+			return false
+		}
+		return appErr.Code == CodeOperationCanceled || errors.Is(err, context.Canceled)
+	}
+	return errors.Is(err, context.Canceled)
+}
+
+// clientClosedError returns err as a client closed request error. An AppError
+// keeps its message and fields; it is not modified.
+func clientClosedError(err error) *AppError {
+	message := err.Error()
+	if appErr, ok := AsAppError(err); ok {
+		message = appErr.Message
+	}
+	return wrap(StackPolicy{Capture: StackCaptureNever}, 0, err, message).AsClientClosed()
+}
@@ -0,0 +1,72 @@
+package xerrs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestError(t *testing.T) {
+	ctx := WithRequestError(context.Background())
+	assert.NoError(t, RequestError(ctx))
+
+	err := New("boom")
+	SetRequestError(ctx, err)
+	assert.Equal(t, err, RequestError(ctx))
+}
+
+func TestWithRequestError_SharesSlot(t *testing.T) {
+	outer := WithRequestError(context.Background())
+	inner := WithRequestError(context.WithValue(outer, struct{}{}, "value"))
+
+	err := New("boom")
+	SetRequestError(inner, err)
+	assert.Equal(t, err, RequestError(outer))
+}
+
+func TestSetRequestError_WithoutSlot(t *testing.T) {
+	ctx := context.Background()
+	SetRequestError(ctx, New("boom"))
+	assert.NoError(t, RequestError(ctx))
+}
+
+func TestSetRequestError_Canceled(t *testing.T) {
+	t.Run("Request Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithRequestError(context.Background()))
+		cancel()
+		SetRequestError(ctx, fmt.Errorf("query: %w", context.Canceled))
+
+		appErr, ok := AsAppError(RequestError(ctx))
+		require.True(t, ok)
+		assert.Equal(t, ErrorTypeClientClosed, appErr.Type)
+		assert.Equal(t, CodeClientClosedRequest, appErr.Code)
+		assert.Equal(t, StatusClientClosedRequest, appErr.GetHTTPStatus())
+		assert.True(t, errors.Is(appErr, context.Canceled))
+	})
+
+	t.Run("Wrapped Request Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithRequestError(context.Background()))
+		cancel()
+		wrapped := Wrap(ctx.Err(), "query users")
+		SetRequestError(ctx, wrapped)
+
+		appErr, ok := AsAppError(RequestError(ctx))
+		require.True(t, ok)
+		assert.Equal(t, ErrorTypeClientClosed, appErr.Type)
+		assert.Equal(t, "query users", appErr.Message)
+		assert.True(t, errors.Is(appErr, context.Canceled))
+		assert.Equal(t, CodeOperationCanceled, wrapped.Code)
+	})
+
+	t.Run("Request Live", func(t *testing.T) {
+		ctx := WithRequestError(context.Background())
+		SetRequestError(ctx, context.Canceled)
+
+		assert.Equal(t, context.Canceled, RequestError(ctx))
+		assert.Equal(t, ErrorTypeInternal, Wrap(RequestError(ctx), "job").Type)
+	})
+}
@@ -0,0 +1,161 @@
+package xerrs
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Default values applied to zero fields of a RetryPolicy.
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+	DefaultRetryMultiplier     = 2.0
+)
+
+// Clock abstracts time for Retry so tests can control it.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RetryPolicy configures Retry. Zero fields use the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction in either direction (0-1).
+	Jitter float64
+	// MaxElapsed stops retrying when the next attempt would start after this
+	// much time since the first one. Zero means no limit.
+	MaxElapsed time.Duration
+	// Classifier reports whether an error is retryable. Defaults to IsRetryable.
+	Classifier func(err error) bool
+	// Clock provides the current time and timers. Defaults to the system clock.
+	Clock Clock
+}
+
+// withDefaults returns a copy of p with zero fields set to their defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+	if p.Classifier == nil {
+		p.Classifier = IsRetryable
+	}
+	if p.Clock == nil {
+		p.Clock = systemClock{}
+	}
+	return p
+}
+
+// backoff returns the delay before the attempt following the given one.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable,
+// or the policy gives up. Delays grow exponentially and honor the
+// RetryAfter of returned AppErrors. Retry stops when ctx is done.
+//
+// An error that is not retryable is returned unchanged when the first attempt
+// fails with it. Otherwise, when Retry gives up or meets an error that is not
+// retryable, it returns an *AppError with the type, code and message of the
+// last error that wraps it and records the number of attempts, available
+// through Attempts. When ctx stopped the retries, ctx.Err() is part of its chain.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	start := policy.Clock.Now()
+
+	attempt := 0
+	for {
+		attempt++
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if !policy.Classifier(err) {
+			if attempt == 1 {
+				return err
+			}
+			return retryError(err, nil, attempt)
+		}
+		if ctx.Err() != nil {
+			return retryError(err, ctx.Err(), attempt)
+		}
+		if attempt >= policy.MaxAttempts {
+			return retryError(err, nil, attempt)
+		}
+
+		delay := policy.backoff(attempt)
+		if appErr, ok := AsAppError(err); ok && appErr.RetryAfter() > delay {
+			delay = appErr.RetryAfter()
+		}
+		if policy.MaxElapsed > 0 && policy.Clock.Now().Add(delay).Sub(start) > policy.MaxElapsed {
+			return retryError(err, nil, attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return retryError(err, ctx.Err(), attempt)
+		case <-policy.Clock.After(delay):
+		}
+	}
+}
+
+// retryError returns the error of Retry when it gives up: an AppError with
+// the fields of the last error that wraps it, joined with ctxErr when the
+// context stopped the retries, and records the attempt count.
+func retryError(err, ctxErr error, attempts int) *AppError {
+	last := ToAppError(err)
+	if ctxErr != nil && !stderrors.Is(err, ctxErr) {
+		err = stderrors.Join(err, ctxErr)
+	}
+	out := *last
+	out.fields = slices.Clip(out.fields)
+	var stack errorStack
+	out.cause, stack = wrapCause(GetStackPolicy(), 1, err, "")
+	// The stacks of the last error are reached through the cause.
+	out.pending = nil
+	out.addStack(stack)
+	out.attempts = attempts
+	return &out
+}
+
+// Attempts returns the number of attempts made by Retry, or zero for errors
+// not returned by Retry.
+func (e *AppError) Attempts() int {
+	if e == nil {
+		return 0
+	}
+	return e.attempts
+}
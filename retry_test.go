@@ -0,0 +1,189 @@
+package xerrs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock whose timers fire immediately and advance the time.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// failing returns a function that fails with errs in order, then succeeds.
+func failing(calls *int, errs ...error) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+func TestRetry_SucceedsAfterRetryableErrors(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, Clock: clock},
+		failing(&calls, New("x").AsServiceUnavailable(), New("x").AsTooManyRequests()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.sleeps)
+}
+
+func TestRetry_StopsOnPermanentError(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	permanent := New("bad input").AsInvalidInput()
+	err := Retry(context.Background(), RetryPolicy{Clock: clock}, failing(&calls, permanent))
+
+	assert.Same(t, permanent, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, clock.sleeps)
+	assert.Equal(t, "bad input", permanent.Message)
+}
+
+func TestRetry_PermanentErrorAfterRetries(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	permanent := New("user not found").AsResourceNotFound()
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, Clock: clock},
+		failing(&calls, New("down").AsServiceUnavailable(), New("down").AsServiceUnavailable(), permanent))
+
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, appErr.Attempts())
+	assert.Equal(t, ErrorTypeNotFound, appErr.Type)
+	assert.Equal(t, "user not found", appErr.Message)
+	assert.True(t, errors.Is(err, permanent))
+	assert.Zero(t, permanent.Attempts())
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	last := New("still down").AsServiceUnavailable()
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 1500 * time.Millisecond, Clock: clock},
+		failing(&calls, New("down").AsServiceUnavailable(), New("down").AsServiceUnavailable(), last))
+
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, appErr.Attempts())
+	assert.Equal(t, ErrorTypeUnavailable, appErr.Type)
+	assert.Equal(t, "still down", appErr.Message)
+	assert.True(t, errors.Is(err, last))
+	assert.Zero(t, last.Attempts())
+	assert.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond}, clock.sleeps)
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxElapsed: 4 * time.Second, Clock: clock},
+		failing(&calls, New("x").AsServiceUnavailable(), New("x").AsServiceUnavailable(), New("x").AsServiceUnavailable()))
+
+	require.Error(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.sleeps)
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	err := Retry(context.Background(), RetryPolicy{InitialBackoff: time.Second, Clock: clock},
+		failing(&calls, New("slow down").AsTooManyRequests().WithRetryAfter(5*time.Second)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second}, clock.sleeps)
+}
+
+func TestRetry_Classifier(t *testing.T) {
+	clock := newFakeClock()
+	var calls int
+	err := Retry(context.Background(), RetryPolicy{Clock: clock, Classifier: func(err error) bool {
+		appErr, ok := AsAppError(err)
+		return ok && appErr.HasCode(CodeResourceNotFound)
+	}}, failing(&calls, New("not yet").AsResourceNotFound()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_StopsOnContextCanceled(t *testing.T) {
+	clock := newFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 5, Clock: clock}, func(context.Context) error {
+		calls++
+		cancel()
+		return New("down").AsServiceUnavailable()
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.True(t, errors.Is(err, context.Canceled))
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, CodeServiceUnavailable, appErr.Code)
+	assert.Equal(t, 1, appErr.Attempts())
+
+	calls = 0
+	err = Retry(context.Background(), RetryPolicy{MaxAttempts: 5, Clock: clock}, failing(&calls, context.Canceled))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, calls)
+}
+
+// blockingClock is a Clock whose timers never fire.
+type blockingClock struct{}
+
+func (blockingClock) Now() time.Time                       { return time.Time{} }
+func (blockingClock) After(time.Duration) <-chan time.Time { return nil }
+
+func TestRetry_CanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 5, Clock: blockingClock{}}, func(context.Context) error {
+		calls++
+		time.AfterFunc(time.Millisecond, cancel)
+		return New("down").AsServiceUnavailable()
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.True(t, errors.Is(err, context.Canceled))
+	appErr, ok := AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, "down", appErr.Message)
+	assert.Equal(t, 1, appErr.Attempts())
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}.withDefaults()
+	for range 100 {
+		delay := policy.backoff(1)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+}
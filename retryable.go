@@ -0,0 +1,98 @@
+package xerrs
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Retryable reports whether the operation that produced the error can be
+// retried. An explicit WithRetryable value takes precedence; otherwise types
+// registered as retryable (rate limit, unavailable and timeouts by default),
+// external timeouts, database deadlocks and serialization failures are
+// retryable, and everything else is permanent.
+func (e *AppError) Retryable() bool {
+	if e == nil {
+		return false
+	}
+	if e.retryable != nil {
+		return *e.retryable
+	}
+	return isRetryableClass(e.Type, e.Code)
+}
+
+// WithRetryable overrides the default retryability classification.
+func (e *AppError) WithRetryable(retryable bool) *AppError {
+	if e == nil {
+		return nil
+	}
+	e.retryable = &retryable
+	return e
+}
+
+// WithRetryAfter sets how long clients should wait before retrying.
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	if e == nil {
+		return nil
+	}
+	if d > 0 {
+		e.retryAfter = d
+	}
+	return e
+}
+
+// RetryAfter returns the delay set with WithRetryAfter, or zero.
+func (e *AppError) RetryAfter() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.retryAfter
+}
+
+// IsRetryable reports whether err can be retried. The first AppError in the
+// chain decides; other errors are classified like Wrap would classify them.
+// Canceled contexts are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if appErr, ok := AsAppError(err); ok {
+		return appErr.Retryable()
+	}
+	return isRetryableClass(detectErrorTypeAndCode(err))
+}
+
+// isRetryableClass reports whether errors of the given type and code are
+// retryable by default.
+func isRetryableClass(errorType ErrorType, code string) bool {
+	if opts, ok := LookupErrorType(errorType); ok && opts.Retryable {
+		return true
+	}
+	switch code {
+	case CodeExternalTimeout, CodeExternalUnavailable, CodeServiceUnavailable, CodeGatewayTimeout,
+		CodeDatabaseDeadlock, CodeDatabaseSerialization:
+		return true
+	}
+	return false
+}
+
+// SetRetryAfterHeader sets the Retry-After header, in whole seconds, when err
+// carries a retry delay and maps to 429 Too Many Requests or 503 Service
+// Unavailable. It reports whether the header was set.
+func SetRetryAfterHeader(h http.Header, err error) bool {
+	appErr, ok := AsAppError(err)
+	if !ok || appErr.RetryAfter() <= 0 {
+		return false
+	}
+	switch appErr.GetHTTPStatus() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return false
+	}
+	seconds := int64((appErr.RetryAfter() + time.Second - 1) / time.Second)
+	h.Set("Retry-After", strconv.FormatInt(seconds, 10))
+	return true
+}
@@ -0,0 +1,107 @@
+package xerrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *AppError
+		expected bool
+	}{
+		{"Rate Limit", New("x").AsTooManyRequests(), true},
+		{"Service Unavailable", New("x").AsServiceUnavailable(), true},
+		{"External Timeout", New("x").AsServiceTimeout(), true},
+		{"Database Deadlock", New("x").AsDatabaseDeadlock(), true},
+		{"Database Serialization", New("x").AsDatabaseSerialization(), true},
+		{"Request Timeout", New("x").AsRequestTimeout(), true},
+		{"Gateway Timeout", New("x").AsGatewayTimeout(), true},
+		{"Client Closed", New("x").AsClientClosed(), false},
+		{"Not Implemented", New("x").AsNotImplemented(), false},
+		{"Validation", New("x").AsInvalidInput(), false},
+		{"Authentication", New("x").AsTokenExpired(), false},
+		{"Authorization", New("x").AsAccessDenied(), false},
+		{"Not Found", New("x").AsResourceNotFound(), false},
+		{"Internal", New("x"), false},
+		{"Override Permanent", New("x").AsTooManyRequests().WithRetryable(false), false},
+		{"Override Retryable", New("x").AsDatabaseError().WithRetryable(true), true},
+		{"Nil Error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Retryable())
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"AppError", New("x").AsServiceUnavailable(), true},
+		{"Wrapped AppError", fmt.Errorf("call: %w", New("x").AsTooManyRequests()), true},
+		{"Wrapped Permanent AppError", fmt.Errorf("call: %w", New("x").AsInvalidInput()), false},
+		{"Context Canceled", context.Canceled, false},
+		{"Deadlock Message", errors.New("ERROR: deadlock detected (SQLSTATE 40P01)"), true},
+		{"Serialization Message", errors.New("could not serialize access due to concurrent update"), true},
+		{"Gateway Timeout Message", errors.New("upstream request timeout"), true},
+		{"Plain Error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	err := New("slow down").AsTooManyRequests().WithRetryAfter(3 * time.Second)
+	assert.Equal(t, 3*time.Second, err.RetryAfter())
+
+	err.WithRetryAfter(-time.Second)
+	assert.Equal(t, 3*time.Second, err.RetryAfter())
+
+	wrapped := Wrap(err, "rate limited")
+	assert.Equal(t, 3*time.Second, wrapped.RetryAfter())
+	assert.True(t, wrapped.Retryable())
+
+	var nilErr *AppError
+	assert.Nil(t, nilErr.WithRetryAfter(time.Second))
+	assert.Zero(t, nilErr.RetryAfter())
+}
+
+func TestSetRetryAfterHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Rate Limit", New("x").AsTooManyRequests().WithRetryAfter(1500 * time.Millisecond), "2"},
+		{"Unavailable", New("x").AsServiceUnavailable().WithRetryAfter(30 * time.Second), "30"},
+		{"Without Delay", New("x").AsTooManyRequests(), ""},
+		{"Other Status", New("x").AsServiceTimeout().WithRetryAfter(time.Second), ""},
+		{"Plain Error", errors.New("x"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			set := SetRetryAfterHeader(h, tt.err)
+			assert.Equal(t, tt.expected != "", set)
+			assert.Equal(t, tt.expected, h.Get("Retry-After"))
+		})
+	}
+}
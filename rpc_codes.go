@@ -0,0 +1,141 @@
+package xerrs
+
+import "strings"
+
+// ErrorInfo domain and metadata keys used by the RPC integrations to carry
+// the error type, code and HTTP status.
+const (
+	ErrorInfoDomain             = "xerrs"
+	ErrorInfoMetadataType       = "type"
+	ErrorInfoMetadataHTTPStatus = "http_status"
+)
+
+// grpcCodeMappings maps gRPC codes to error types and codes. It is shared by
+// the gRPC, Connect and Twirp integrations.
+var grpcCodeMappings = map[uint32]StatusMapping{
+	grpcCanceled:           {Type: ErrorTypeClientClosed, Code: CodeClientClosedRequest},
+	grpcUnknown:            {Type: ErrorTypeInternal, Code: CodeInternalError},
+	grpcInvalidArgument:    {Type: ErrorTypeValidation, Code: CodeInvalidInput},
+	grpcDeadlineExceeded:   {Type: ErrorTypeGatewayTimeout, Code: CodeGatewayTimeout},
+	grpcNotFound:           {Type: ErrorTypeNotFound, Code: CodeResourceNotFound},
+	grpcAlreadyExists:      {Type: ErrorTypeConflict, Code: CodeResourceExists},
+	grpcPermissionDenied:   {Type: ErrorTypeAuthorization, Code: CodeAccessDenied},
+	grpcResourceExhausted:  {Type: ErrorTypeRateLimit, Code: CodeRateLimitExceeded},
+	grpcFailedPrecondition: {Type: ErrorTypePreconditionFailed, Code: CodePreconditionFailed},
+	grpcAborted:            {Type: ErrorTypeConflict, Code: CodeResourceExists},
+	grpcOutOfRange:         {Type: ErrorTypeValidation, Code: CodeInvalidRange},
+	grpcUnimplemented:      {Type: ErrorTypeNotImplemented, Code: CodeNotImplemented},
+	grpcInternal:           {Type: ErrorTypeInternal, Code: CodeInternalError},
+	grpcUnavailable:        {Type: ErrorTypeUnavailable, Code: CodeServiceUnavailable},
+	grpcDataLoss:           {Type: ErrorTypeInternal, Code: CodeInternalError},
+	grpcUnauthenticated:    {Type: ErrorTypeAuthentication, Code: CodeAuthRequired},
+}
+
+// grpcCodeNames holds the names of the gRPC codes, as spelled by
+// google.rpc.Code, indexed by code.
+var grpcCodeNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// GRPCCodeName returns the google.rpc.Code name of a gRPC code, such as
+// "INVALID_ARGUMENT". Codes out of range are named "UNKNOWN".
+func GRPCCodeName(code uint32) string {
+	if int(code) < len(grpcCodeNames) {
+		return grpcCodeNames[code]
+	}
+	return grpcCodeNames[grpcUnknown]
+}
+
+// GRPCCodeByName returns the gRPC code with the google.rpc.Code name, ignoring
+// case. It reports false for unknown names.
+func GRPCCodeByName(name string) (uint32, bool) {
+	for code, codeName := range grpcCodeNames {
+		if strings.EqualFold(codeName, name) {
+			return uint32(code), true
+		}
+	}
+	return grpcUnknown, false
+}
+
+// RPCCodeNames names gRPC codes in protocols that spell them as the lowercase
+// google.rpc.Code names, such as Connect and Twirp, with a few exceptions.
+type RPCCodeNames struct {
+	// Renamed holds the names that differ from the lowercase google.rpc.Code
+	// names, by gRPC code.
+	Renamed map[uint32]string
+	// Extra holds the names without a gRPC equivalent, and the gRPC codes
+	// they decode as.
+	Extra map[string]uint32
+}
+
+// Name returns the name of a gRPC code. OK and codes out of range are named
+// as Unknown.
+func (n RPCCodeNames) Name(code uint32) string {
+	if name, ok := n.Renamed[code]; ok {
+		return name
+	}
+	if code == 0 {
+		code = grpcUnknown
+	}
+	return strings.ToLower(GRPCCodeName(code))
+}
+
+// Code returns the gRPC code of a name. Unknown names, and google.rpc.Code
+// names spelled differently, decode as Unknown.
+func (n RPCCodeNames) Code(name string) uint32 {
+	if code, ok := n.Extra[name]; ok {
+		return code
+	}
+	for code, renamed := range n.Renamed {
+		if renamed == name {
+			return code
+		}
+	}
+	if code, ok := GRPCCodeByName(name); ok && code != 0 && n.Name(code) == name {
+		return code
+	}
+	return grpcUnknown
+}
+
+// GRPCCode returns the google.golang.org/grpc/codes.Code value for the error:
+// the one of its type, except that conflicts other than CodeResourceExists
+// and transient database conflicts map to Aborted.
+func (e *AppError) GRPCCode() uint32 {
+	if e == nil {
+		return grpcUnknown
+	}
+	switch e.Code {
+	case CodeDatabaseDeadlock, CodeDatabaseSerialization:
+		return grpcAborted
+	}
+	if e.Type == ErrorTypeConflict && e.Code != CodeResourceExists {
+		return grpcAborted
+	}
+	return e.Type.GRPCCode()
+}
+
+// GRPCCodeMapping returns the error type and code for a
+// google.golang.org/grpc/codes.Code value, for errors received from services
+// that do not report them. Unknown codes map to internal errors.
+func GRPCCodeMapping(code uint32) StatusMapping {
+	if mapping, ok := grpcCodeMappings[code]; ok {
+		return mapping
+	}
+	return grpcCodeMappings[grpcInternal]
+}
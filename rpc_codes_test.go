@@ -0,0 +1,104 @@
+package xerrs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppErrorGRPCCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *AppError
+		want uint32
+	}{
+		{"nil", nil, grpcUnknown},
+		{"validation", NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad"), grpcInvalidArgument},
+		{"resource exists", NewAppError(ErrorTypeConflict, CodeResourceExists, "dup"), grpcAlreadyExists},
+		{"other conflict", NewAppError(ErrorTypeConflict, "VERSION_MISMATCH", "stale"), grpcAborted},
+		{"deadlock", NewAppError(ErrorTypeInternal, CodeDatabaseDeadlock, "deadlock"), grpcAborted},
+		{"serialization", NewAppError(ErrorTypeInternal, CodeDatabaseSerialization, "retry"), grpcAborted},
+		{"unavailable", NewAppError(ErrorTypeUnavailable, CodeServiceUnavailable, "down"), grpcUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.GRPCCode())
+		})
+	}
+}
+
+func TestGRPCCodeMapping(t *testing.T) {
+	tests := []struct {
+		code uint32
+		want StatusMapping
+	}{
+		{grpcInvalidArgument, StatusMapping{Type: ErrorTypeValidation, Code: CodeInvalidInput}},
+		{grpcOutOfRange, StatusMapping{Type: ErrorTypeValidation, Code: CodeInvalidRange}},
+		{grpcAborted, StatusMapping{Type: ErrorTypeConflict, Code: CodeResourceExists}},
+		{grpcCanceled, StatusMapping{Type: ErrorTypeClientClosed, Code: CodeClientClosedRequest}},
+		{grpcDeadlineExceeded, StatusMapping{Type: ErrorTypeGatewayTimeout, Code: CodeGatewayTimeout}},
+		{grpcDataLoss, StatusMapping{Type: ErrorTypeInternal, Code: CodeInternalError}},
+		{99, StatusMapping{Type: ErrorTypeInternal, Code: CodeInternalError}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, GRPCCodeMapping(tt.code), "code %d", tt.code)
+	}
+}
+
+func TestGRPCCodeName(t *testing.T) {
+	tests := []struct {
+		code uint32
+		name string
+	}{
+		{0, "OK"},
+		{grpcCanceled, "CANCELLED"},
+		{grpcInvalidArgument, "INVALID_ARGUMENT"},
+		{grpcDataLoss, "DATA_LOSS"},
+		{grpcUnauthenticated, "UNAUTHENTICATED"},
+		{42, "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, GRPCCodeName(tt.code))
+		})
+	}
+}
+
+func TestGRPCCodeByName(t *testing.T) {
+	code, ok := GRPCCodeByName("not_found")
+	assert.True(t, ok)
+	assert.Equal(t, grpcNotFound, code)
+
+	code, ok = GRPCCodeByName("NOT_A_CODE")
+	assert.False(t, ok)
+	assert.Equal(t, grpcUnknown, code)
+}
+
+func TestRPCCodeNames(t *testing.T) {
+	names := RPCCodeNames{
+		Renamed: map[uint32]string{grpcCanceled: "canceled"},
+		Extra:   map[string]uint32{"malformed": grpcInvalidArgument},
+	}
+	tests := []struct {
+		code uint32
+		name string
+	}{
+		{grpcCanceled, "canceled"},
+		{grpcInvalidArgument, "invalid_argument"},
+		{grpcDataLoss, "data_loss"},
+		{grpcUnauthenticated, "unauthenticated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, names.Name(tt.code))
+			assert.Equal(t, tt.code, names.Code(tt.name))
+		})
+	}
+	assert.Equal(t, "unknown", names.Name(0))
+	assert.Equal(t, grpcInvalidArgument, names.Code("malformed"))
+	assert.Equal(t, grpcUnknown, names.Code("cancelled"))
+	assert.Equal(t, grpcUnknown, names.Code("INVALID_ARGUMENT"))
+	assert.Equal(t, grpcUnknown, names.Code("ok"))
+}
@@ -0,0 +1,227 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorSchemaHeader is the request header selecting the version of the JSON
+// error response schema, and the response header reporting it.
+const ErrorSchemaHeader = "X-Error-Schema"
+
+// ErrorSchemaParam is the Accept media type parameter selecting the version
+// of the JSON error response schema, as in "application/json; version=2".
+const ErrorSchemaParam = "version"
+
+// ErrorSchema is a version of the JSON error response schema. Versions
+// after 1 include their number in a "version" member, so that
+// DecodeErrorResponse can tell them apart.
+type ErrorSchema interface {
+	// Encode returns the JSON encoding of err.
+	Encode(err *AppError) ([]byte, error)
+	// Decode returns the AppError encoded in data.
+	Decode(data []byte) (*AppError, error)
+}
+
+// errorSchemas holds the registered schemas by version.
+var (
+	errorSchemas = map[int]ErrorSchema{
+		1: errorSchemaV1{},
+		2: errorSchemaV2{},
+	}
+	errorSchemasMu sync.RWMutex
+)
+
+// defaultErrorSchema holds the version used when a request selects none.
+var defaultErrorSchema atomic.Int64
+
+func init() {
+	defaultErrorSchema.Store(1)
+}
+
+// RegisterErrorSchema registers a schema version, or replaces a built-in one.
+// It returns an error if version is lower than 1 or schema is nil.
+func RegisterErrorSchema(version int, schema ErrorSchema) error {
+	if version < 1 {
+		return New(fmt.Sprintf("invalid error schema version %d", version)).AsInvalidInput()
+	}
+	if schema == nil {
+		return New(fmt.Sprintf("nil error schema for version %d", version)).AsInvalidInput()
+	}
+	errorSchemasMu.Lock()
+	defer errorSchemasMu.Unlock()
+	errorSchemas[version] = schema
+	return nil
+}
+
+// MustRegisterErrorSchema is like RegisterErrorSchema but panics on error.
+func MustRegisterErrorSchema(version int, schema ErrorSchema) {
+	if err := RegisterErrorSchema(version, schema); err != nil {
+		panic(err)
+	}
+}
+
+// LookupErrorSchema returns the schema registered for version.
+func LookupErrorSchema(version int) (ErrorSchema, bool) {
+	errorSchemasMu.RLock()
+	defer errorSchemasMu.RUnlock()
+	schema, ok := errorSchemas[version]
+	return schema, ok
+}
+
+// SetDefaultErrorSchema sets the version used for requests that select
+// none. It defaults to 1, so that existing clients keep the original schema.
+// It returns an error if version is not registered.
+func SetDefaultErrorSchema(version int) error {
+	if _, ok := LookupErrorSchema(version); !ok {
+		return New(fmt.Sprintf("unknown error schema version %d", version)).AsInvalidInput()
+	}
+	defaultErrorSchema.Store(int64(version))
+	return nil
+}
+
+// MustSetDefaultErrorSchema is like SetDefaultErrorSchema but panics on error.
+func MustSetDefaultErrorSchema(version int) {
+	if err := SetDefaultErrorSchema(version); err != nil {
+		panic(err)
+	}
+}
+
+// DefaultErrorSchema returns the version used for requests that select none.
+func DefaultErrorSchema() int {
+	return int(defaultErrorSchema.Load())
+}
+
+// NegotiateErrorSchema returns the schema version selected by r: the one in
+// the X-Error-Schema header, or the version parameter of the application/json
+// range of the Accept header. Unregistered versions are ignored, falling back
+// to the default version.
+func NegotiateErrorSchema(r *http.Request) int {
+	if version, ok := registeredVersion(r.Header.Get(ErrorSchemaHeader)); ok {
+		return version
+	}
+	for _, accepted := range parseAccept(r.Header.Get("Accept")) {
+		if accepted.mediaType != MediaTypeJSON {
+			continue
+		}
+		if version, ok := registeredVersion(accepted.params[ErrorSchemaParam]); ok {
+			return version
+		}
+	}
+	return DefaultErrorSchema()
+}
+
+// registeredVersion parses a schema version and reports whether it is registered.
+func registeredVersion(value string) (int, bool) {
+	version, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	_, ok := LookupErrorSchema(version)
+	return version, ok
+}
+
+// EncodeErrorResponse returns the JSON encoding of err in the given schema
+// version. Errors that are not AppErrors are classified as by Wrap.
+func EncodeErrorResponse(err error, version int) ([]byte, error) {
+	schema, ok := LookupErrorSchema(version)
+	if !ok {
+		return nil, New(fmt.Sprintf("unknown error schema version %d", version)).AsInvalidInput()
+	}
+	return schema.Encode(ToAppError(err))
+}
+
+// DecodeErrorResponse decodes a JSON error response in any registered schema
+// version, read from its "version" member.
+func DecodeErrorResponse(data []byte) (*AppError, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, Wrap(err, "decode error response").AsInvalidFormat()
+	}
+	if header.Version == 0 {
+		header.Version = 1
+	}
+	schema, ok := LookupErrorSchema(header.Version)
+	if !ok {
+		return nil, New(fmt.Sprintf("unknown error schema version %d", header.Version)).AsInvalidFormat()
+	}
+	return schema.Decode(data)
+}
+
+// decodedAppError builds an AppError read from a response, without a stack
+// trace. A zero status means the default status of the type.
+func decodedAppError(errorType ErrorType, code, message, details string, status int, fields []FieldError) *AppError {
+	appErr := newAppError(StackPolicy{Capture: StackCaptureNever}, 1, errorType, code, message).
+		WithDetails(details)
+	if status != 0 {
+		appErr.WithHTTPStatus(status)
+	}
+	appErr.fields = fields
+	appErr.site = errorSite{}
+	return appErr
+}
+
+// errorSchemaV1 is the original schema: the AppError JSON encoding. It keeps
+// the fingerprint, so that existing clients see no change; version 2 leaves
+// it out.
+type errorSchemaV1 struct{}
+
+// Encode implements ErrorSchema.
+func (errorSchemaV1) Encode(err *AppError) ([]byte, error) {
+	return json.Marshal(err)
+}
+
+// Decode implements ErrorSchema.
+func (errorSchemaV1) Decode(data []byte) (*AppError, error) {
+	var appErr AppError
+	if err := json.Unmarshal(data, &appErr); err != nil {
+		return nil, Wrap(err, "decode error response").AsInvalidFormat()
+	}
+	return &appErr, nil
+}
+
+// errorSchemaV2JSON is the JSON representation of schema version 2, which
+// renames http_status to status and lists field errors in errors. It leaves
+// out the fingerprint, which identifies code sites and is meant for internal
+// grouping only.
+type errorSchemaV2JSON struct {
+	Version int          `json:"version"`
+	Type    ErrorType    `json:"type"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Status  int          `json:"status"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// errorSchemaV2 is schema version 2.
+type errorSchemaV2 struct{}
+
+// Encode implements ErrorSchema.
+func (errorSchemaV2) Encode(err *AppError) ([]byte, error) {
+	return json.Marshal(errorSchemaV2JSON{
+		Version: 2,
+		Type:    err.Type,
+		Code:    err.Code,
+		Message: err.Message,
+		Details: err.Details,
+		Status:  err.GetHTTPStatus(),
+		Errors:  err.fields,
+	})
+}
+
+// Decode implements ErrorSchema.
+func (errorSchemaV2) Decode(data []byte) (*AppError, error) {
+	var in errorSchemaV2JSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, Wrap(err, "decode error response").AsInvalidFormat()
+	}
+	return decodedAppError(in.Type, in.Code, in.Message, in.Details, in.Status, in.Errors), nil
+}
@@ -0,0 +1,178 @@
+package xerrs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSchemaTestError returns the error encoded by the golden files. Its
+// fingerprint is pinned, since the default one hashes the test function names.
+func newSchemaTestError() *AppError {
+	return WithStackPolicy(StackPolicy{Capture: StackCaptureNever}).
+		NewAppError(ErrorTypeValidation, CodeValidationError, "invalid user").
+		WithDetails("check the request body").
+		WithFingerprint("schema-test").
+		WithFieldError("email", CodeRequiredField, "email is required").
+		WithFieldError("address.zip", CodeInvalidFormat, "zip must have 5 digits")
+}
+
+func withRegisteredErrorSchema(tb testing.TB, version int, schema ErrorSchema) {
+	tb.Helper()
+	previous, existed := LookupErrorSchema(version)
+	require.NoError(tb, RegisterErrorSchema(version, schema))
+	tb.Cleanup(func() {
+		errorSchemasMu.Lock()
+		defer errorSchemasMu.Unlock()
+		if existed {
+			errorSchemas[version] = previous
+		} else {
+			delete(errorSchemas, version)
+		}
+	})
+}
+
+func TestErrorSchema_Golden(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		name := "v" + strconv.Itoa(version)
+		t.Run(name, func(t *testing.T) {
+			data, err := EncodeErrorResponse(newSchemaTestError(), version)
+			require.NoError(t, err)
+			assertGolden(t, "schema/"+name, string(data)+"\n")
+		})
+	}
+}
+
+func TestErrorSchema_RoundTrip(t *testing.T) {
+	original := newSchemaTestError().WithHTTPStatus(http.StatusUnprocessableEntity)
+
+	for _, version := range []int{1, 2} {
+		data, err := EncodeErrorResponse(original, version)
+		require.NoError(t, err)
+
+		decoded, err := DecodeErrorResponse(data)
+		require.NoError(t, err, "version %d", version)
+		assert.Equal(t, original.Type, decoded.Type)
+		assert.Equal(t, original.Code, decoded.Code)
+		assert.Equal(t, original.Message, decoded.Message)
+		assert.Equal(t, original.Details, decoded.Details)
+		assert.Equal(t, http.StatusUnprocessableEntity, decoded.GetHTTPStatus())
+		assert.Equal(t, original.FieldErrors(), decoded.FieldErrors())
+	}
+}
+
+func TestDecodeErrorResponse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Not JSON", "oops"},
+		{"Unknown Version", `{"version": 99, "code": "X"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeErrorResponse([]byte(tt.data))
+			appErr, ok := AsAppError(err)
+			require.True(t, ok)
+			assert.Equal(t, CodeInvalidFormat, appErr.Code)
+		})
+	}
+
+	_, err := EncodeErrorResponse(New("boom"), 99)
+	assert.Error(t, err)
+}
+
+func TestDecodeErrorResponse_DefaultStatus(t *testing.T) {
+	decoded, err := DecodeErrorResponse([]byte(`{"type": "NOT_FOUND", "code": "RESOURCE_NOT_FOUND", "message": "missing"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, decoded.GetHTTPStatus())
+}
+
+func TestNegotiateErrorSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		accept   string
+		expected int
+	}{
+		{"Default", "", "", 1},
+		{"Header", "2", "", 2},
+		{"Accept Parameter", "", "application/json; version=2", 2},
+		{"Header Wins", "1", "application/json; version=2", 1},
+		{"Other Media Type", "", "text/html; version=2", 1},
+		{"Unknown Header", "9", "application/json; version=2", 2},
+		{"Unknown Version", "", "application/json; version=9", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(ErrorSchemaHeader, tt.header)
+			}
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			assert.Equal(t, tt.expected, NegotiateErrorSchema(r))
+		})
+	}
+}
+
+func TestSetDefaultErrorSchema(t *testing.T) {
+	require.NoError(t, SetDefaultErrorSchema(2))
+	t.Cleanup(func() { MustSetDefaultErrorSchema(1) })
+
+	assert.Equal(t, 2, DefaultErrorSchema())
+	assert.Equal(t, 2, NegotiateErrorSchema(httptest.NewRequest(http.MethodGet, "/", nil)))
+
+	err := SetDefaultErrorSchema(9)
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown error schema version 9", ToAppError(err).Message)
+	}
+	assert.Equal(t, 2, DefaultErrorSchema())
+	assert.Panics(t, func() { MustSetDefaultErrorSchema(9) })
+}
+
+// codeOnlySchema is a test schema that encodes only the code.
+type codeOnlySchema struct{}
+
+func (codeOnlySchema) Encode(err *AppError) ([]byte, error) {
+	return []byte(`{"version": 3, "code": "` + err.Code + `"}`), nil
+}
+
+func (codeOnlySchema) Decode([]byte) (*AppError, error) {
+	return NewAppError(ErrorTypeInternal, "DECODED_V3", "decoded"), nil
+}
+
+func TestRegisterErrorSchema(t *testing.T) {
+	withRegisteredErrorSchema(t, 3, codeOnlySchema{})
+
+	data, err := EncodeErrorResponse(New("boom"), 3)
+	require.NoError(t, err)
+	decoded, err := DecodeErrorResponse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "DECODED_V3", decoded.Code)
+
+	assert.Error(t, RegisterErrorSchema(0, codeOnlySchema{}))
+	assert.Error(t, RegisterErrorSchema(4, nil))
+	assert.Panics(t, func() { MustRegisterErrorSchema(4, nil) })
+	_, ok := LookupErrorSchema(4)
+	assert.False(t, ok)
+}
+
+func TestRenderer_ErrorSchema(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/json; version=2")
+	rec := httptest.NewRecorder()
+	NewRenderer(nil).Render(rec, r, newSchemaTestError())
+
+	assert.Equal(t, "2", rec.Header().Get(ErrorSchemaHeader))
+	assert.Equal(t, []string{"Accept", ErrorSchemaHeader}, rec.Header().Values("Vary"))
+	decoded, err := DecodeErrorResponse(rec.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, newSchemaTestError().FieldErrors(), decoded.FieldErrors())
+	assert.Contains(t, rec.Body.String(), `"status":400`)
+}
@@ -0,0 +1,116 @@
+// Package sentryx reports xerrs errors to Sentry with tags, extras, grouping
+// and levels derived from the AppError fields.
+package sentryx
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/getsentry/sentry-go"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// Tag and extra keys set on reported events.
+const (
+	TagType       = "xerrs.type"
+	TagCode       = "xerrs.code"
+	TagHTTPStatus = "xerrs.http_status"
+	ExtraDetails  = "xerrs.details"
+	ExtraCauses   = "xerrs.causes"
+)
+
+// Options configures ReportWithOptions.
+type Options struct {
+	// ReportClientErrors also reports client errors, such as those that map
+	// to 4xx statuses, which are skipped by default.
+	ReportClientErrors bool
+	// GroupByFingerprint refines the grouping by the AppError fingerprint,
+	// splitting each type and code by message template and call site.
+	GroupByFingerprint bool
+}
+
+// Report sends err to Sentry using the hub from ctx, or the current hub when
+// ctx has none. Client errors, as told by AppError.IsClientError, are skipped. It returns the
+// ID of the captured event, or nil when nothing was sent.
+func Report(ctx context.Context, err error) *sentry.EventID {
+	return ReportWithOptions(ctx, err, Options{})
+}
+
+// ReportWithOptions is like Report with the given options.
+func ReportWithOptions(ctx context.Context, err error, opts Options) *sentry.EventID {
+	if err == nil {
+		return nil
+	}
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	appErr, ok := xerrs.AsAppError(err)
+	if !ok {
+		return hub.CaptureException(err)
+	}
+	if appErr.IsClientError() && !opts.ReportClientErrors {
+		return nil
+	}
+
+	var eventID *sentry.EventID
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(map[string]string{
+			TagType:       string(appErr.Type),
+			TagCode:       appErr.Code,
+			TagHTTPStatus: strconv.Itoa(appErr.GetHTTPStatus()),
+		})
+		if appErr.Details != "" {
+			scope.SetExtra(ExtraDetails, appErr.Details)
+		}
+		if causes := causeMessages(err); len(causes) > 0 {
+			scope.SetExtra(ExtraCauses, causes)
+		}
+		fingerprint := Fingerprint(appErr)
+		if opts.GroupByFingerprint {
+			fingerprint = append(fingerprint, appErr.Fingerprint())
+		}
+		scope.SetFingerprint(fingerprint)
+		scope.SetLevel(Level(appErr))
+		eventID = hub.CaptureException(err)
+	})
+	return eventID
+}
+
+// Fingerprint returns the Sentry grouping fingerprint for an AppError: its
+// type and code.
+func Fingerprint(err *xerrs.AppError) []string {
+	return []string{string(err.Type), err.Code}
+}
+
+// Level returns the Sentry level for an AppError from its severity: by
+// default warning for client errors and error for server errors.
+func Level(err *xerrs.AppError) sentry.Level {
+	switch err.Severity() {
+	case xerrs.SeverityInfo:
+		return sentry.LevelInfo
+	case xerrs.SeverityWarning:
+		return sentry.LevelWarning
+	case xerrs.SeverityFatal:
+		return sentry.LevelFatal
+	default:
+		return sentry.LevelError
+	}
+}
+
+// causeMessages returns the distinct messages of each layer in the cause chain,
+// outermost first.
+func causeMessages(err error) []string {
+	var messages []string
+	for err != nil {
+		msg := err.Error()
+		if len(messages) == 0 || messages[len(messages)-1] != msg {
+			messages = append(messages, msg)
+		}
+		err = errors.UnwrapOnce(err)
+	}
+	return messages
+}
@@ -0,0 +1,133 @@
+package sentryx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// fakeTransport records events instead of sending them over the network.
+type fakeTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *fakeTransport) Configure(sentry.ClientOptions) {}
+
+func (t *fakeTransport) Flush(time.Duration) bool { return true }
+
+func (t *fakeTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *fakeTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+// newTestContext returns a context carrying a hub bound to a fake transport.
+func newTestContext(t *testing.T) (context.Context, *fakeTransport) {
+	t.Helper()
+	transport := &fakeTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	require.NoError(t, err)
+	hub := sentry.NewHub(client, sentry.NewScope())
+	return sentry.SetHubOnContext(context.Background(), hub), transport
+}
+
+func TestReport(t *testing.T) {
+	ctx, transport := newTestContext(t)
+	err := xerrs.Wrap(xerrs.New("connection refused").AsDatabaseConnection().WithDetails("host=db-1"), "load user")
+
+	eventID := Report(ctx, err)
+	require.NotNil(t, eventID)
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, string(xerrs.ErrorTypeInternal), event.Tags[TagType])
+	assert.Equal(t, xerrs.CodeDatabaseConnection, event.Tags[TagCode])
+	assert.Equal(t, "500", event.Tags[TagHTTPStatus])
+	assert.Equal(t, "host=db-1", event.Extra[ExtraDetails])
+	assert.Equal(t, []string{err.Error(), "load user: connection refused", "connection refused"}, event.Extra[ExtraCauses])
+	assert.Equal(t, []string{string(xerrs.ErrorTypeInternal), xerrs.CodeDatabaseConnection}, event.Fingerprint)
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.NotEmpty(t, event.Exception)
+}
+
+func TestReportWithOptions_GroupByFingerprint(t *testing.T) {
+	ctx, transport := newTestContext(t)
+	err := xerrs.New("connection refused").AsDatabaseConnection()
+
+	require.NotNil(t, ReportWithOptions(ctx, err, Options{GroupByFingerprint: true}))
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, []string{string(xerrs.ErrorTypeInternal), xerrs.CodeDatabaseConnection, err.Fingerprint()}, events[0].Fingerprint)
+}
+
+func TestReport_SkipsClientErrors(t *testing.T) {
+	ctx, transport := newTestContext(t)
+
+	assert.Nil(t, Report(ctx, xerrs.New("bad input").AsInvalidInput()))
+	assert.Empty(t, transport.Events())
+}
+
+func TestReport_RegisteredClass(t *testing.T) {
+	const dependencyDown xerrs.ErrorType = "SENTRYX_DEPENDENCY_DOWN"
+	xerrs.MustRegisterErrorType(dependencyDown, xerrs.ErrorTypeOptions{HTTPStatus: http.StatusFailedDependency, Class: xerrs.ClassServer})
+	ctx, transport := newTestContext(t)
+
+	require.NotNil(t, Report(ctx, xerrs.NewAppError(dependencyDown, "DEPENDENCY", "dependency down")))
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, sentry.LevelError, events[0].Level)
+	assert.Equal(t, "424", events[0].Tags[TagHTTPStatus])
+}
+
+func TestReportWithOptions_ClientErrors(t *testing.T) {
+	ctx, transport := newTestContext(t)
+
+	eventID := ReportWithOptions(ctx, xerrs.New("bad input").AsInvalidInput(), Options{ReportClientErrors: true})
+	require.NotNil(t, eventID)
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, sentry.LevelWarning, events[0].Level)
+	assert.Equal(t, xerrs.CodeInvalidInput, events[0].Tags[TagCode])
+}
+
+func TestReport_PlainError(t *testing.T) {
+	ctx, transport := newTestContext(t)
+
+	require.NotNil(t, Report(ctx, errors.New("plain failure")))
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.NotContains(t, events[0].Tags, TagType)
+}
+
+func TestReport_NilError(t *testing.T) {
+	ctx, transport := newTestContext(t)
+
+	assert.Nil(t, Report(ctx, nil))
+	assert.Empty(t, transport.Events())
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, sentry.LevelWarning, Level(xerrs.New("x").AsResourceNotFound()))
+	assert.Equal(t, sentry.LevelError, Level(xerrs.New("x").AsServiceUnavailable()))
+}
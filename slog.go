@@ -0,0 +1,208 @@
+package xerrs
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Attribute keys used when an AppError is rendered as a slog group.
+const (
+	LogKeyType        = "type"
+	LogKeyCode        = "code"
+	LogKeyMessage     = "message"
+	LogKeyHTTPStatus  = "http_status"
+	LogKeyDetails     = "details"
+	LogKeyCause       = "cause"
+	LogKeyStack       = "stack"
+	LogKeyFingerprint = "fingerprint"
+	LogKeyUpstream    = "upstream"
+	LogKeyError       = "error"
+)
+
+// LogValue implements slog.LogValuer so that an AppError is logged as a group
+// of its structured fields instead of its Error() string.
+func (e *AppError) LogValue() slog.Value {
+	return e.logValue(0)
+}
+
+// LogValueWithStack returns the same group as LogValue with at most maxLines
+// stack frames attached under the "stack" key.
+func (e *AppError) LogValueWithStack(maxLines int) slog.Value {
+	return e.logValue(maxLines)
+}
+
+// logValue builds the slog group for the error, attaching up to stackLines
+// stack frames when stackLines is positive.
+func (e *AppError) logValue(stackLines int) slog.Value {
+	if e == nil {
+		return slog.StringValue("<nil>")
+	}
+	attrs := []slog.Attr{
+		slog.String(LogKeyType, string(e.Type)),
+		slog.String(LogKeyCode, e.Code),
+		slog.String(LogKeyMessage, e.Message),
+		slog.Int(LogKeyHTTPStatus, e.GetHTTPStatus()),
+		slog.String(LogKeyFingerprint, e.Fingerprint()),
+	}
+	if e.Details != "" {
+		attrs = append(attrs, slog.String(LogKeyDetails, e.Details))
+	}
+	if root := e.UnwrapAll(); root != nil && root.Error() != e.Message {
+		attrs = append(attrs, slog.String(LogKeyCause, root.Error()))
+	}
+	if u := e.upstream; u != nil {
+		upstream := []any{slog.String("service", u.Service)}
+		if u.Endpoint != "" {
+			upstream = append(upstream, slog.String("endpoint", u.Endpoint))
+		}
+		if u.Status != 0 {
+			upstream = append(upstream, slog.Int("status", u.Status))
+		}
+		if u.Latency > 0 {
+			upstream = append(upstream, slog.Duration("latency", u.Latency))
+		}
+		attrs = append(attrs, slog.Group(LogKeyUpstream, upstream...))
+	}
+	if stackLines > 0 {
+		frames := e.StackFramesWithOptions(StackFrameOptions{
+			TrimGOROOT:      true,
+			TrimModulePaths: true,
+			MaxFrames:       stackLines,
+		})
+		lines := make([]string, len(frames))
+		for i, frame := range frames {
+			lines[i] = frame.String()
+		}
+		attrs = append(attrs, slog.Any(LogKeyStack, lines))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// SlogHandlerOptions configures the handler returned by NewSlogHandler.
+type SlogHandlerOptions struct {
+	// StackLines is the maximum number of stack trace lines attached to an
+	// expanded AppError. Zero disables stack output.
+	StackLines int
+	// KeepLevel disables the level adjustment based on the error type.
+	KeepLevel bool
+}
+
+// SlogHandler wraps another slog.Handler, expanding error attributes into
+// structured groups and deriving the record level from the error type.
+type SlogHandler struct {
+	next slog.Handler
+	opts SlogHandlerOptions
+	// attrLevel is the strongest level derived from errors attached with
+	// WithAttrs, applied to every record when raised is set.
+	attrLevel slog.Level
+	raised    bool
+}
+
+// NewSlogHandler creates a SlogHandler that forwards records to next.
+// A nil opts uses the zero SlogHandlerOptions.
+func NewSlogHandler(next slog.Handler, opts *SlogHandlerOptions) *SlogHandler {
+	h := &SlogHandler{next: next}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the wrapped handler handles records at the given
+// level. Since an error attribute can raise the level of a record, records
+// below the threshold of the wrapped handler are let through as long as it
+// handles errors; Handle then drops those that were not raised.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.next.Enabled(ctx, level) {
+		return true
+	}
+	return !h.opts.KeepLevel && level < slog.LevelError && h.next.Enabled(ctx, slog.LevelError)
+}
+
+// Handle expands error attributes and forwards the record to the wrapped handler.
+// When an attribute holds an AppError, including attributes added with
+// WithAttrs, the record level is raised to Warn for client errors and Error
+// for server errors; it is never lowered.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := r.Level
+	if h.raised && !h.opts.KeepLevel {
+		level = max(level, h.attrLevel)
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		expanded, appErr := h.expandAttr(a)
+		if appErr != nil && !h.opts.KeepLevel {
+			level = max(level, levelForError(appErr))
+		}
+		attrs = append(attrs, expanded)
+		return true
+	})
+	if !h.next.Enabled(ctx, level) {
+		return nil
+	}
+
+	out := slog.NewRecord(r.Time, level, r.Message, r.PC)
+	out.AddAttrs(attrs...)
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs returns a new SlogHandler whose wrapped handler has the given
+// attributes, with any error values expanded. The level derived from those
+// errors applies to every record of the new handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := *h
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		var appErr *AppError
+		expanded[i], appErr = h.expandAttr(a)
+		if appErr == nil {
+			continue
+		}
+		if level := levelForError(appErr); !out.raised || level > out.attrLevel {
+			out.attrLevel, out.raised = level, true
+		}
+	}
+	out.next = h.next.WithAttrs(expanded)
+	return &out
+}
+
+// WithGroup returns a new SlogHandler whose wrapped handler uses the given group.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	out := *h
+	out.next = h.next.WithGroup(name)
+	return &out
+}
+
+// expandAttr replaces an error-valued attribute with its structured form and
+// returns the AppError found in its chain, if any. When err wraps the AppError,
+// its full message is kept under the "error" key.
+func (h *SlogHandler) expandAttr(a slog.Attr) (slog.Attr, *AppError) {
+	if kind := a.Value.Kind(); kind != slog.KindAny && kind != slog.KindLogValuer {
+		return a, nil
+	}
+	err, ok := a.Value.Any().(error)
+	if !ok || err == nil {
+		return a, nil
+	}
+	appErr, ok := AsAppError(err)
+	if !ok {
+		return slog.String(a.Key, err.Error()), nil
+	}
+	value := appErr.logValue(h.opts.StackLines)
+	if err != error(appErr) {
+		value = slog.GroupValue(append([]slog.Attr{slog.String(LogKeyError, err.Error())}, value.Group()...)...)
+	}
+	return slog.Attr{Key: a.Key, Value: value}, appErr
+}
+
+// levelForError maps an AppError to a log level based on its severity.
+func levelForError(e *AppError) slog.Level {
+	switch e.Severity() {
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
@@ -0,0 +1,212 @@
+package xerrs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLogLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	return out
+}
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := Wrap(errors.New("connection refused"), "load user").WithDetails("id=42")
+	logger.Info("request failed", "error", err)
+
+	line := decodeLogLine(t, &buf)
+	group, ok := line["error"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, string(ErrorTypeExternal), group[LogKeyType])
+	assert.Equal(t, CodeExternalError, group[LogKeyCode])
+	assert.Equal(t, "load user", group[LogKeyMessage])
+	assert.Equal(t, float64(502), group[LogKeyHTTPStatus])
+	assert.Equal(t, "id=42", group[LogKeyDetails])
+	assert.Equal(t, "connection refused", group[LogKeyCause])
+	assert.Equal(t, err.Fingerprint(), group[LogKeyFingerprint])
+	assert.NotContains(t, group, LogKeyStack)
+}
+
+func TestLogValueWithStack(t *testing.T) {
+	err := New("boom")
+	value := err.LogValueWithStack(2)
+
+	var stack []string
+	for _, a := range value.Group() {
+		if a.Key == LogKeyStack {
+			stack = a.Value.Any().([]string)
+		}
+	}
+	assert.Len(t, stack, 2)
+}
+
+func TestLogValue_NilError(t *testing.T) {
+	var err *AppError
+	assert.Equal(t, "<nil>", err.LogValue().String())
+}
+
+func TestSlogHandler_Level(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Client Error", New("bad input").AsInvalidInput(), "WARN"},
+		{"Server Error", New("db down").AsDatabaseConnection(), "ERROR"},
+		{"Wrapped AppError", errors.Join(New("gone").AsResourceNotFound()), "WARN"},
+		{"Plain Error", errors.New("plain"), "INFO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), nil))
+			logger.Info("handled", "err", tt.err)
+
+			line := decodeLogLine(t, &buf)
+			assert.Equal(t, tt.expected, line["level"])
+		})
+	}
+}
+
+func TestSlogHandler_NeverLowersLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), nil))
+	logger.Error("handled", "err", New("bad input").AsInvalidInput())
+
+	line := decodeLogLine(t, &buf)
+	assert.Equal(t, "ERROR", line["level"])
+}
+
+func TestSlogHandler_RaisesFilteredRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Server Error", New("db down").AsDatabaseConnection(), "ERROR"},
+		{"Client Error", New("bad input").AsInvalidInput(), ""},
+		{"Plain Error", errors.New("plain"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError})
+			slog.New(NewSlogHandler(next, nil)).Info("handled", "err", tt.err)
+
+			if tt.expected == "" {
+				assert.Zero(t, buf.Len())
+				return
+			}
+			line := decodeLogLine(t, &buf)
+			assert.Equal(t, tt.expected, line["level"])
+		})
+	}
+}
+
+func TestSlogHandler_KeepLevelFiltersRecords(t *testing.T) {
+	var buf bytes.Buffer
+	next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError})
+	slog.New(NewSlogHandler(next, &SlogHandlerOptions{KeepLevel: true})).Info("handled", "err", New("boom"))
+
+	assert.Zero(t, buf.Len())
+}
+
+func TestSlogHandler_ExpandsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewSlogHandler(slog.NewJSONHandler(&buf, nil), &SlogHandlerOptions{StackLines: 3})
+	logger := slog.New(handler).With("base", New("setup").AsConfiguration())
+
+	logger.Info("handled", "err", errors.Join(New("gone").AsResourceNotFound()))
+
+	line := decodeLogLine(t, &buf)
+	group, ok := line["err"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, CodeResourceNotFound, group[LogKeyCode])
+	assert.Len(t, group[LogKeyStack], 3)
+
+	base, ok := line["base"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, CodeConfigurationError, base[LogKeyCode])
+}
+
+func TestSlogHandler_WithAttrsLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Server Error", New("db down").AsDatabaseConnection(), "ERROR"},
+		{"Client Error", New("bad input").AsInvalidInput(), "WARN"},
+		{"Plain Error", errors.New("plain"), "INFO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), nil))
+			logger.With("error", tt.err).WithGroup("request").Info("handled", "id", 42)
+
+			line := decodeLogLine(t, &buf)
+			assert.Equal(t, tt.expected, line["level"])
+		})
+	}
+}
+
+func TestSlogHandler_WithAttrsKeepsStrongestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), nil))
+	logger.With("first", New("db down").AsDatabaseConnection()).
+		With("second", New("bad input").AsInvalidInput()).
+		Info("handled")
+
+	line := decodeLogLine(t, &buf)
+	assert.Equal(t, "ERROR", line["level"])
+}
+
+func TestSlogHandler_KeepsWrappingMessage(t *testing.T) {
+	gone := New("gone").AsResourceNotFound()
+	wrapped := fmt.Errorf("ctx: %w", gone)
+	tests := []struct {
+		name     string
+		err      error
+		expected any
+	}{
+		{"Wrapped AppError", wrapped, wrapped.Error()},
+		{"AppError", gone, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil), nil)).Info("handled", "err", tt.err)
+
+			group, ok := decodeLogLine(t, &buf)["err"].(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, group[LogKeyError])
+			assert.Equal(t, "gone", group[LogKeyMessage])
+		})
+	}
+}
+
+func TestSlogHandler_KeepLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewSlogHandler(slog.NewJSONHandler(&buf, nil), &SlogHandlerOptions{KeepLevel: true})
+	slog.New(handler).Info("handled", "err", New("boom"))
+
+	line := decodeLogLine(t, &buf)
+	assert.Equal(t, "INFO", line["level"])
+}
@@ -0,0 +1,211 @@
+package xerrs
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/errbase"
+)
+
+// Frame is a single resolved stack frame. Its JSON form is suitable for log pipelines.
+type Frame struct {
+	Function string `json:"function"`
+	Package  string `json:"package"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String returns the frame as "function file:line".
+func (f Frame) String() string {
+	return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+}
+
+// StackFrameOptions controls how StackFramesWithOptions resolves and filters frames.
+type StackFrameOptions struct {
+	// TrimGOROOT strips the GOROOT source prefix from standard library file paths.
+	TrimGOROOT bool
+	// TrimModulePaths replaces the directory of non-standard-library files with
+	// their package import path, mirroring the output of -trimpath.
+	TrimModulePaths bool
+	// SkipRuntime drops frames from the runtime and testing packages.
+	SkipRuntime bool
+	// MaxFrames limits the number of returned frames. Zero means no limit.
+	MaxFrames int
+}
+
+// skippedPackages lists the packages dropped by StackFrameOptions.SkipRuntime.
+var skippedPackages = []string{"runtime", "testing"}
+
+// StackFrames returns the resolved stack frames of the error, starting with the
+// full stack captured where the error was created, followed by the frames each
+// later wrap point adds on top of it.
+func (e *AppError) StackFrames() []Frame {
+	return e.StackFramesWithOptions(StackFrameOptions{})
+}
+
+// StackFramesWithOptions returns the stack frames of the error filtered and
+// trimmed according to opts.
+func (e *AppError) StackFramesWithOptions(opts StackFrameOptions) []Frame {
+	if e == nil {
+		return nil
+	}
+	if e.decodedFrames != nil {
+		return filterFrames(nil, e.decodedFrames, opts)
+	}
+	var frames []Frame
+	for _, pcs := range collectStackPCs(e) {
+		// Frames are resolved one stack at a time so that MaxFrames
+		// avoids resolving the stacks it does not reach.
+		frames = filterFrames(frames, resolveFrames(pcs), opts)
+		if opts.MaxFrames > 0 && len(frames) == opts.MaxFrames {
+			return frames
+		}
+	}
+	return frames
+}
+
+// filterFrames appends the resolved frames selected by opts to frames, up to
+// opts.MaxFrames.
+func filterFrames(frames, resolved []Frame, opts StackFrameOptions) []Frame {
+	for _, frame := range resolved {
+		if opts.MaxFrames > 0 && len(frames) == opts.MaxFrames {
+			break
+		}
+		if opts.SkipRuntime && isSkippedPackage(frame.Package) {
+			continue
+		}
+		frames = append(frames, trimFrame(frame, opts))
+	}
+	return frames
+}
+
+// collectStackPCs walks the cause chain and returns the program counters of each
+// captured stack, innermost first, with frames shared with the inner stack removed.
+func collectStackPCs(err error) [][]uintptr {
+	var stacks [][]uintptr
+	for err != nil {
+		if appErr, ok := err.(*AppError); ok {
+			pending := appErr.pendingStacks()
+			for i := len(pending) - 1; i >= 0; i-- {
+				stacks = append(stacks, pending[i])
+			}
+			err = appErr.cause
+			continue
+		}
+		if provider, ok := err.(errbase.StackTraceProvider); ok {
+			trace := provider.StackTrace()
+			pcs := make([]uintptr, len(trace))
+			for i, f := range trace {
+				pcs[i] = uintptr(f)
+			}
+			stacks = append(stacks, pcs)
+		}
+		err = errors.UnwrapOnce(err)
+	}
+
+	// Reverse so the creation point comes first, then elide shared suffixes.
+	for i, j := 0, len(stacks)-1; i < j; i, j = i+1, j-1 {
+		stacks[i], stacks[j] = stacks[j], stacks[i]
+	}
+	for i := len(stacks) - 1; i > 0; i-- {
+		stacks[i] = elideSharedSuffix(stacks[i-1], stacks[i])
+	}
+	return stacks
+}
+
+// pendingStackTrace formats the stacks recorded under StackCaptureServerErrors
+// like the stack traces of the errors library, or returns "" when the error
+// reports none.
+func (e *AppError) pendingStackTrace() string {
+	stacks := e.pendingStacks()
+	var b strings.Builder
+	for i, pcs := range stacks {
+		if i > 0 {
+			pcs = elideSharedSuffix(stacks[i-1], pcs)
+		}
+		b.WriteString("\n-- stack trace:")
+		for _, f := range resolveFrames(pcs) {
+			fmt.Fprintf(&b, "\n  | %s\n  | \t%s:%d", f.Function, f.File, f.Line)
+		}
+	}
+	return b.String()
+}
+
+// elideSharedSuffix removes the trailing frames of next that also end prev.
+func elideSharedSuffix(prev, next []uintptr) []uintptr {
+	i, j := len(prev)-1, len(next)-1
+	for i >= 0 && j >= 0 && prev[i] == next[j] {
+		i--
+		j--
+	}
+	return next[:j+1]
+}
+
+// resolveFrames converts program counters into Frames.
+func resolveFrames(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := make([]Frame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		if f.Function != "" || f.File != "" {
+			frames = append(frames, Frame{
+				Function: f.Function,
+				Package:  packageName(f.Function),
+				File:     f.File,
+				Line:     f.Line,
+			})
+		}
+		if !more {
+			return frames
+		}
+	}
+}
+
+// packageName extracts the import path from a fully qualified function name.
+func packageName(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// isSkippedPackage reports whether pkg belongs to the runtime or testing packages.
+func isSkippedPackage(pkg string) bool {
+	for _, skipped := range skippedPackages {
+		if pkg == skipped || strings.HasPrefix(pkg, skipped+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// trimFrame shortens the file path of a frame according to opts.
+func trimFrame(f Frame, opts StackFrameOptions) Frame {
+	if f.Package == "" {
+		return f
+	}
+	if isStdlibFrame(f) {
+		if opts.TrimGOROOT {
+			f.File = path.Join(f.Package, path.Base(f.File))
+		}
+		return f
+	}
+	if opts.TrimModulePaths {
+		f.File = path.Join(f.Package, path.Base(f.File))
+	}
+	return f
+}
+
+// isStdlibFrame reports whether the frame belongs to a standard library package
+// located under GOROOT/src.
+func isStdlibFrame(f Frame) bool {
+	first, _, _ := strings.Cut(f.Package, "/")
+	return !strings.Contains(first, ".") && strings.Contains(f.File, "/src/"+f.Package+"/")
+}
@@ -0,0 +1,185 @@
+package xerrs
+
+import (
+	stderrors "errors"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/errbase"
+)
+
+// StackCapture selects when a stack trace is captured for a new error.
+type StackCapture int
+
+const (
+	// StackCaptureAlways captures a stack trace for every error.
+	StackCaptureAlways StackCapture = iota
+	// StackCaptureNever never captures stack traces.
+	StackCaptureNever
+	// StackCaptureServerErrors reports stack traces only for server error
+	// types. The program counters are recorded when the error is created,
+	// and only resolved when the stack is read, so the decision follows the
+	// final type: New(msg).AsInvalidFormat() reports no stack and costs no
+	// more than the program counters.
+	StackCaptureServerErrors
+	// StackCaptureSampled captures stack traces for a percentage of errors.
+	StackCaptureSampled
+)
+
+// StackPolicy controls stack trace capture in New, NewAppError, Wrap and WithCause.
+type StackPolicy struct {
+	Capture StackCapture
+	// SamplePercent is the percentage (0-100) of errors that capture a stack
+	// trace when Capture is StackCaptureSampled.
+	SamplePercent float64
+}
+
+// stackPolicy holds the global policy; nil means StackCaptureAlways.
+var stackPolicy atomic.Pointer[StackPolicy]
+
+// SetStackPolicy sets the global stack capture policy.
+func SetStackPolicy(policy StackPolicy) {
+	stackPolicy.Store(&policy)
+}
+
+// GetStackPolicy returns the global stack capture policy.
+func GetStackPolicy() StackPolicy {
+	if policy := stackPolicy.Load(); policy != nil {
+		return *policy
+	}
+	return StackPolicy{}
+}
+
+// maxStackDepth is the maximum number of frames captured in a stack trace,
+// as in the errors library.
+const maxStackDepth = 32
+
+// shouldCapture reports whether a new cause captures a stack trace right away.
+// Under StackCaptureServerErrors, the program counters are recorded instead
+// and reported only while the error has a server error type.
+func (p StackPolicy) shouldCapture() bool {
+	switch p.Capture {
+	case StackCaptureNever, StackCaptureServerErrors:
+		return false
+	case StackCaptureSampled:
+		return rand.Float64()*100 < p.SamplePercent
+	default:
+		return true
+	}
+}
+
+// errorStack is the stack captured for a new cause: the stack trace attached
+// to the cause, or the program counters recorded under
+// StackCaptureServerErrors. Both are empty when no stack was captured.
+type errorStack struct {
+	trace   errbase.StackTraceProvider
+	pending []uintptr
+}
+
+// newCause creates the leaf cause of a new error. The depth value zero
+// identifies the caller of newCause.
+func newCause(policy StackPolicy, depth int, message string) (error, errorStack) {
+	switch {
+	case policy.Capture == StackCaptureServerErrors:
+		return stderrors.New(message), errorStack{pending: callers(depth + 1)}
+	case policy.shouldCapture():
+		err := errors.NewWithDepth(depth+1, message)
+		return err, errorStack{trace: stackTraceOf(err)}
+	default:
+		return stderrors.New(message), errorStack{}
+	}
+}
+
+// wrapCause wraps err with message. The depth value zero identifies the
+// caller of wrapCause.
+func wrapCause(policy StackPolicy, depth int, err error, message string) (error, errorStack) {
+	switch {
+	case policy.Capture == StackCaptureServerErrors:
+		return errors.WithMessage(err, message), errorStack{pending: callers(depth + 1)}
+	case policy.shouldCapture():
+		err = errors.WrapWithDepth(depth+1, err, message)
+		return err, errorStack{trace: stackTraceOf(err)}
+	default:
+		return errors.WithMessage(err, message), errorStack{}
+	}
+}
+
+// callers returns the program counters of the stack, starting at the given
+// depth. The depth value zero identifies the caller of callers.
+func callers(depth int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(depth+2, pcs[:])
+	return slices.Clone(pcs[:n])
+}
+
+// stackTraceOf returns the outermost layer of err that carries a stack trace.
+func stackTraceOf(err error) errbase.StackTraceProvider {
+	for ; err != nil; err = errors.UnwrapOnce(err) {
+		if provider, ok := err.(errbase.StackTraceProvider); ok {
+			return provider
+		}
+	}
+	return nil
+}
+
+// site returns the creation site recorded by the stack, or walks the stack
+// when none was captured. The depth value zero identifies the caller of site.
+func (s errorStack) site(depth int) errorSite {
+	switch {
+	case s.pending != nil:
+		return errorSite{pcs: s.pending}
+	case s.trace != nil:
+		return errorSite{trace: s.trace}
+	default:
+		return errorSite{pcs: captureSite(depth + 1)}
+	}
+}
+
+// addStack records the stack captured for a new cause of the error.
+func (e *AppError) addStack(stack errorStack) {
+	if stack.pending != nil {
+		e.pending = append(slices.Clip(e.pending), stack.pending)
+	}
+}
+
+// pendingStacks returns the stacks recorded under StackCaptureServerErrors,
+// innermost first, when the error has a server error type. The decision is
+// made when the stack is read, so that it follows the final type of the error.
+func (e *AppError) pendingStacks() [][]uintptr {
+	if len(e.pending) == 0 || !e.Type.IsServerError() {
+		return nil
+	}
+	return e.pending
+}
+
+// ErrorFactory creates errors with a per-call stack capture policy instead of
+// the global one.
+type ErrorFactory struct {
+	policy StackPolicy
+}
+
+// WithStackPolicy returns an ErrorFactory that uses the given policy.
+//
+//	err := xerrs.WithStackPolicy(xerrs.StackPolicy{Capture: xerrs.StackCaptureNever}).
+//		NewAppError(xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput, "email is required")
+func WithStackPolicy(policy StackPolicy) ErrorFactory {
+	return ErrorFactory{policy: policy}
+}
+
+// New creates a new internal AppError like New.
+func (f ErrorFactory) New(message string) *AppError {
+	return newAppError(f.policy, 1, ErrorTypeInternal, CodeInternalError, message)
+}
+
+// NewAppError creates a new AppError like NewAppError.
+func (f ErrorFactory) NewAppError(errorType ErrorType, code, message string) *AppError {
+	return newAppError(f.policy, 1, errorType, code, message)
+}
+
+// Wrap wraps an existing error like Wrap.
+func (f ErrorFactory) Wrap(err error, message string) *AppError {
+	return wrap(f.policy, 1, err, message)
+}
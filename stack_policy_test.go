@@ -0,0 +1,207 @@
+package xerrs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withGlobalStackPolicy sets the global policy for the duration of a test.
+func withGlobalStackPolicy(tb testing.TB, policy StackPolicy) {
+	tb.Helper()
+	previous := GetStackPolicy()
+	SetStackPolicy(policy)
+	tb.Cleanup(func() { SetStackPolicy(previous) })
+}
+
+func TestStackPolicy_ShouldCapture(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   StackPolicy
+		expected bool
+	}{
+		{"Always", StackPolicy{Capture: StackCaptureAlways}, true},
+		{"Never", StackPolicy{Capture: StackCaptureNever}, false},
+		{"Server Errors - Deferred", StackPolicy{Capture: StackCaptureServerErrors}, false},
+		{"Sampled - 100%", StackPolicy{Capture: StackCaptureSampled, SamplePercent: 100}, true},
+		{"Sampled - 0%", StackPolicy{Capture: StackCaptureSampled, SamplePercent: 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.shouldCapture())
+		})
+	}
+}
+
+func TestSetStackPolicy(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureServerErrors})
+
+	assert.Equal(t, StackCaptureServerErrors, GetStackPolicy().Capture)
+	assert.Empty(t, NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad input").StackFrames())
+	assert.NotEmpty(t, New("boom").StackFrames())
+}
+
+func TestWithStackPolicy(t *testing.T) {
+	factory := WithStackPolicy(StackPolicy{Capture: StackCaptureNever})
+
+	err := factory.NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad input")
+	assert.Equal(t, "bad input", err.Message)
+	assert.Empty(t, err.StackFrames())
+
+	err = factory.New("boom")
+	assert.Equal(t, ErrorTypeInternal, err.Type)
+	assert.Empty(t, err.StackFrames())
+
+	root := errors.New("root")
+	err = factory.Wrap(root, "wrapped")
+	assert.Empty(t, err.StackFrames())
+	assert.Equal(t, root, err.UnwrapAll())
+	assert.Contains(t, err.Unwrap().Error(), "root")
+}
+
+func TestWithStackPolicy_CapturesCaller(t *testing.T) {
+	err := WithStackPolicy(StackPolicy{}).New("boom")
+	frames := err.StackFrames()
+
+	if assert.NotEmpty(t, frames) {
+		assert.Equal(t, "github.com/hotfixfirst/go-xerrs.TestWithStackPolicy_CapturesCaller", frames[0].Function)
+	}
+}
+
+func TestWithCause_StackPolicy(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureNever})
+
+	err := NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad input").WithCause(errors.New("root"))
+	assert.Empty(t, err.StackFrames())
+	assert.Contains(t, err.Unwrap().Error(), "root")
+}
+
+func TestStackCaptureServerErrors_FollowsType(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureServerErrors})
+
+	tests := []struct {
+		name      string
+		err       func() *AppError
+		withStack bool
+	}{
+		{"New As Client", func() *AppError { return New("bad date").AsInvalidFormat() }, false},
+		{"New WithType Client", func() *AppError { return New("bad date").WithType(ErrorTypeValidation) }, false},
+		{"New As Server", func() *AppError { return New("db down").AsDatabaseConnection() }, true},
+		{"Wrap As Client", func() *AppError { return Wrap(errors.New("EOF"), "decode").AsInvalidFormat() }, false},
+		{"Client As Server", func() *AppError {
+			return NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad input").AsDatabaseError()
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			assert.Equal(t, tt.withStack, len(err.StackFrames()) > 0)
+		})
+	}
+}
+
+func TestStackCaptureServerErrors_ReportsCreationSite(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureServerErrors})
+
+	err := NewAppError(ErrorTypeValidation, CodeInvalidInput, "bad input").AsDatabaseConnection()
+	frames := err.StackFrames()
+
+	if assert.NotEmpty(t, frames) {
+		assert.Equal(t, "github.com/hotfixfirst/go-xerrs.TestStackCaptureServerErrors_ReportsCreationSite", frames[0].Function)
+	}
+	assert.Equal(t, "bad input", err.Cause().Error())
+}
+
+func TestStackCaptureServerErrors_GetStackTrace(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureServerErrors})
+
+	err := Wrap(New("db down"), "load user")
+	assert.Contains(t, err.GetStackTrace(), "TestStackCaptureServerErrors_GetStackTrace")
+	assert.Contains(t, err.GetStackTraceLines(), "-- stack trace:")
+
+	err.AsInvalidFormat()
+	assert.NotContains(t, err.GetStackTrace(), "-- stack trace:")
+}
+
+func TestStackCaptureAlways_KeepsStackOnRetype(t *testing.T) {
+	withGlobalStackPolicy(t, StackPolicy{Capture: StackCaptureAlways})
+
+	assert.NotEmpty(t, New("bad date").AsInvalidFormat().StackFrames())
+}
+
+func BenchmarkNewAppError_StackAlways(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureAlways})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = NewAppError(ErrorTypeValidation, CodeInvalidInput, "email is required")
+	}
+}
+
+func BenchmarkNewAppError_StackNever(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureNever})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = NewAppError(ErrorTypeValidation, CodeInvalidInput, "email is required")
+	}
+}
+
+func BenchmarkNewAppError_StackServerErrors(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureServerErrors})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = NewAppError(ErrorTypeValidation, CodeInvalidInput, "email is required")
+	}
+}
+
+func BenchmarkNewAppError_StackSampled(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureSampled, SamplePercent: 10})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = NewAppError(ErrorTypeValidation, CodeInvalidInput, "email is required")
+	}
+}
+
+func BenchmarkWrap_StackAlways(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureAlways})
+	root := errors.New("invalid character")
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = Wrap(root, "decode request")
+	}
+}
+
+func BenchmarkWrap_StackNever(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureNever})
+	root := errors.New("invalid character")
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = Wrap(root, "decode request")
+	}
+}
+
+func BenchmarkNewAsInvalidFormat_StackAlways(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureAlways})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = New("bad date").AsInvalidFormat()
+	}
+}
+
+func BenchmarkNewAsInvalidFormat_StackNever(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureNever})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = New("bad date").AsInvalidFormat()
+	}
+}
+
+func BenchmarkNewAsInvalidFormat_StackServerErrors(b *testing.B) {
+	withGlobalStackPolicy(b, StackPolicy{Capture: StackCaptureServerErrors})
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = New("bad date").AsInvalidFormat()
+	}
+}
@@ -0,0 +1,96 @@
+package xerrs
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStackTestError() *AppError {
+	return New("created")
+}
+
+func TestStackFrames(t *testing.T) {
+	err := newStackTestError()
+	frames := err.StackFrames()
+
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/hotfixfirst/go-xerrs.newStackTestError", frames[0].Function)
+	assert.Equal(t, "github.com/hotfixfirst/go-xerrs", frames[0].Package)
+	assert.True(t, strings.HasSuffix(frames[0].File, "stack_test.go"))
+	assert.Greater(t, frames[0].Line, 0)
+}
+
+func TestStackFrames_WrapPoints(t *testing.T) {
+	inner := newStackTestError()
+	outer := Wrap(inner, "wrapped")
+
+	frames := outer.StackFrames()
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/hotfixfirst/go-xerrs.newStackTestError", frames[0].Function)
+
+	var wrapFrames int
+	for _, f := range frames {
+		if f.Function == "github.com/hotfixfirst/go-xerrs.TestStackFrames_WrapPoints" {
+			wrapFrames++
+		}
+	}
+	assert.Equal(t, 2, wrapFrames, "creation stack and wrap point should both reference the test")
+}
+
+func TestStackFramesWithOptions(t *testing.T) {
+	err := Wrap(errors.New("root"), "wrapped")
+
+	all := err.StackFrames()
+	filtered := err.StackFramesWithOptions(StackFrameOptions{
+		TrimGOROOT:      true,
+		TrimModulePaths: true,
+		SkipRuntime:     true,
+	})
+
+	assert.Less(t, len(filtered), len(all))
+	for _, f := range filtered {
+		assert.NotEqual(t, "runtime", f.Package)
+		assert.NotEqual(t, "testing", f.Package)
+	}
+	require.NotEmpty(t, filtered)
+	assert.Equal(t, "github.com/hotfixfirst/go-xerrs/stack_test.go", filtered[0].File)
+
+	limited := err.StackFramesWithOptions(StackFrameOptions{MaxFrames: 1})
+	assert.Len(t, limited, 1)
+}
+
+func TestStackFrames_NilError(t *testing.T) {
+	var err *AppError
+	assert.Nil(t, err.StackFrames())
+}
+
+func TestFrame_JSON(t *testing.T) {
+	frame := Frame{Function: "pkg.Fn", Package: "pkg", File: "pkg/file.go", Line: 12}
+	data, err := json.Marshal(frame)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"function":"pkg.Fn","package":"pkg","file":"pkg/file.go","line":12}`, string(data))
+	assert.Equal(t, "pkg.Fn pkg/file.go:12", frame.String())
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		function string
+		expected string
+	}{
+		{"github.com/hotfixfirst/go-xerrs.New", "github.com/hotfixfirst/go-xerrs"},
+		{"github.com/hotfixfirst/go-xerrs.(*AppError).Error", "github.com/hotfixfirst/go-xerrs"},
+		{"runtime.goexit", "runtime"},
+		{"main.main.func1", "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			assert.Equal(t, tt.expected, packageName(tt.function))
+		})
+	}
+}
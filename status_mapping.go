@@ -0,0 +1,109 @@
+package xerrs
+
+import (
+	"net/http"
+	"sync"
+)
+
+// StatusMapping is the error type and code an HTTP status maps to.
+type StatusMapping struct {
+	Type ErrorType
+	Code string
+}
+
+// defaultStatusMappings holds the built-in HTTP status mappings.
+var defaultStatusMappings = map[int]StatusMapping{
+	http.StatusBadRequest:                   {ErrorTypeValidation, CodeValidationError},
+	http.StatusUnauthorized:                 {ErrorTypeAuthentication, CodeAuthRequired},
+	http.StatusForbidden:                    {ErrorTypeAuthorization, CodeAccessDenied},
+	http.StatusNotFound:                     {ErrorTypeNotFound, CodeResourceNotFound},
+	http.StatusMethodNotAllowed:             {ErrorTypeMethodNotAllowed, CodeMethodNotAllowed},
+	http.StatusRequestTimeout:               {ErrorTypeRequestTimeout, CodeRequestTimeout},
+	http.StatusConflict:                     {ErrorTypeConflict, CodeResourceExists},
+	http.StatusGone:                         {ErrorTypeGone, CodeResourceGone},
+	http.StatusPreconditionFailed:           {ErrorTypePreconditionFailed, CodePreconditionFailed},
+	http.StatusRequestEntityTooLarge:        {ErrorTypePayloadTooLarge, CodePayloadTooLarge},
+	http.StatusRequestURITooLong:            {ErrorTypeValidation, CodeInvalidInput},
+	http.StatusUnsupportedMediaType:         {ErrorTypeUnsupportedMediaType, CodeUnsupportedMediaType},
+	http.StatusRequestedRangeNotSatisfiable: {ErrorTypeValidation, CodeInvalidRange},
+	http.StatusUnprocessableEntity:          {ErrorTypeUnprocessable, CodeUnprocessableEntity},
+	http.StatusTooManyRequests:              {ErrorTypeRateLimit, CodeRateLimitExceeded},
+	StatusClientClosedRequest:               {ErrorTypeClientClosed, CodeClientClosedRequest},
+	http.StatusInternalServerError:          {ErrorTypeInternal, CodeInternalError},
+	http.StatusNotImplemented:               {ErrorTypeNotImplemented, CodeNotImplemented},
+	http.StatusBadGateway:                   {ErrorTypeExternal, CodeExternalError},
+	http.StatusServiceUnavailable:           {ErrorTypeUnavailable, CodeServiceUnavailable},
+	http.StatusGatewayTimeout:               {ErrorTypeGatewayTimeout, CodeGatewayTimeout},
+}
+
+// StatusMapper maps HTTP statuses to error types and codes. Statuses without
+// an explicit mapping fall back to validation errors for 4xx and internal
+// errors otherwise. It is safe for concurrent use.
+type StatusMapper struct {
+	mu       sync.RWMutex
+	mappings map[int]StatusMapping
+}
+
+// NewStatusMapper returns a StatusMapper initialized with the built-in mappings.
+func NewStatusMapper() *StatusMapper {
+	m := &StatusMapper{mappings: make(map[int]StatusMapping, len(defaultStatusMappings))}
+	for status, mapping := range defaultStatusMappings {
+		m.mappings[status] = mapping
+	}
+	return m
+}
+
+// DefaultStatusMapper is the mapper used by TypeFromHTTPStatus and FromHTTPStatus.
+var DefaultStatusMapper = NewStatusMapper()
+
+// Set maps status to the given type and code.
+func (m *StatusMapper) Set(status int, mapping StatusMapping) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mappings[status] = mapping
+}
+
+// Lookup returns the mapping for status.
+func (m *StatusMapper) Lookup(status int) StatusMapping {
+	m.mu.RLock()
+	mapping, ok := m.mappings[status]
+	m.mu.RUnlock()
+	if ok {
+		return mapping
+	}
+	if status >= 400 && status < 500 {
+		return StatusMapping{Type: ErrorTypeValidation, Code: CodeValidationError}
+	}
+	return StatusMapping{Type: ErrorTypeInternal, Code: CodeInternalError}
+}
+
+// FromHTTPStatus creates an AppError of the type and code mapped to status,
+// keeping status as its HTTP status. An empty message defaults to the status text.
+func (m *StatusMapper) FromHTTPStatus(status int, message string) *AppError {
+	return m.fromHTTPStatus(1, status, message)
+}
+
+// fromHTTPStatus implements FromHTTPStatus. The depth value zero identifies
+// the caller of fromHTTPStatus.
+func (m *StatusMapper) fromHTTPStatus(depth, status int, message string) *AppError {
+	mapping := m.Lookup(status)
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return newAppError(GetStackPolicy(), depth+1, mapping.Type, mapping.Code, message).WithHTTPStatus(status)
+}
+
+// TypeFromHTTPStatus returns the error type mapped to status by DefaultStatusMapper.
+func TypeFromHTTPStatus(status int) ErrorType {
+	return DefaultStatusMapper.Lookup(status).Type
+}
+
+// FromHTTPStatus creates an AppError for status using DefaultStatusMapper.
+func FromHTTPStatus(status int, message string) *AppError {
+	return DefaultStatusMapper.fromHTTPStatus(1, status, message)
+}
+
+// SetStatusMapping maps status to the given type and code in DefaultStatusMapper.
+func SetStatusMapping(status int, mapping StatusMapping) {
+	DefaultStatusMapper.Set(status, mapping)
+}
@@ -0,0 +1,83 @@
+package xerrs
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeFromHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected ErrorType
+	}{
+		{http.StatusBadRequest, ErrorTypeValidation},
+		{http.StatusUnauthorized, ErrorTypeAuthentication},
+		{http.StatusForbidden, ErrorTypeAuthorization},
+		{http.StatusNotFound, ErrorTypeNotFound},
+		{http.StatusMethodNotAllowed, ErrorTypeMethodNotAllowed},
+		{http.StatusRequestTimeout, ErrorTypeRequestTimeout},
+		{http.StatusGone, ErrorTypeGone},
+		{http.StatusConflict, ErrorTypeConflict},
+		{http.StatusPreconditionFailed, ErrorTypePreconditionFailed},
+		{http.StatusRequestEntityTooLarge, ErrorTypePayloadTooLarge},
+		{http.StatusUnsupportedMediaType, ErrorTypeUnsupportedMediaType},
+		{http.StatusUnprocessableEntity, ErrorTypeUnprocessable},
+		{StatusClientClosedRequest, ErrorTypeClientClosed},
+		{http.StatusTooManyRequests, ErrorTypeRateLimit},
+		{http.StatusTeapot, ErrorTypeValidation},
+		{http.StatusInternalServerError, ErrorTypeInternal},
+		{http.StatusNotImplemented, ErrorTypeNotImplemented},
+		{http.StatusBadGateway, ErrorTypeExternal},
+		{http.StatusServiceUnavailable, ErrorTypeUnavailable},
+		{http.StatusGatewayTimeout, ErrorTypeGatewayTimeout},
+		{http.StatusLoopDetected, ErrorTypeInternal},
+		{http.StatusOK, ErrorTypeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.expected, TypeFromHTTPStatus(tt.status))
+		})
+	}
+}
+
+func TestTypeFromHTTPStatus_RoundTrip(t *testing.T) {
+	for _, errorType := range []ErrorType{
+		ErrorTypeValidation, ErrorTypeAuthentication, ErrorTypeAuthorization, ErrorTypeNotFound,
+		ErrorTypeConflict, ErrorTypeRateLimit, ErrorTypeInternal, ErrorTypeExternal, ErrorTypeUnavailable,
+		ErrorTypeMethodNotAllowed, ErrorTypeRequestTimeout, ErrorTypeGone, ErrorTypePreconditionFailed,
+		ErrorTypePayloadTooLarge, ErrorTypeUnsupportedMediaType, ErrorTypeUnprocessable, ErrorTypeClientClosed,
+		ErrorTypeNotImplemented, ErrorTypeGatewayTimeout,
+	} {
+		assert.Equal(t, errorType, TypeFromHTTPStatus(errorType.DefaultHTTPStatus()), errorType)
+	}
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	err := FromHTTPStatus(http.StatusGatewayTimeout, "")
+	assert.Equal(t, ErrorTypeGatewayTimeout, err.Type)
+	assert.Equal(t, CodeGatewayTimeout, err.Code)
+	assert.Equal(t, "Gateway Timeout", err.Message)
+	assert.Equal(t, http.StatusGatewayTimeout, err.GetHTTPStatus())
+	assert.NotEmpty(t, err.GetStackTrace())
+
+	err = FromHTTPStatus(http.StatusGone, "order archived")
+	assert.Equal(t, ErrorTypeGone, err.Type)
+	assert.Equal(t, "order archived", err.Message)
+	assert.Equal(t, http.StatusGone, err.GetHTTPStatus())
+
+	err = FromHTTPStatus(799, "")
+	assert.Equal(t, MsgUnknownError, err.Message)
+}
+
+func TestStatusMapper_Set(t *testing.T) {
+	mapper := NewStatusMapper()
+	mapper.Set(http.StatusPaymentRequired, StatusMapping{Type: ErrorTypeAuthorization, Code: "PAYMENT_REQUIRED"})
+
+	err := mapper.FromHTTPStatus(http.StatusPaymentRequired, "")
+	assert.Equal(t, ErrorTypeAuthorization, err.Type)
+	assert.Equal(t, "PAYMENT_REQUIRED", err.Code)
+	assert.Equal(t, ErrorTypeValidation, TypeFromHTTPStatus(http.StatusPaymentRequired))
+}
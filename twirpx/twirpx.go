@@ -0,0 +1,146 @@
+// Package twirpx encodes and decodes xerrs errors in the Twirp JSON error
+// format.
+package twirpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+// ContentType is the content type of Twirp error responses.
+const ContentType = "application/json"
+
+// Meta keys carrying the AppError fields.
+const (
+	MetaErrorCode  = "error_code"
+	MetaErrorType  = "error_type"
+	MetaHTTPStatus = "http_status"
+	MetaDetails    = "details"
+	MetaRetryAfter = "retry_after"
+)
+
+// Error is the Twirp JSON error format.
+type Error struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// codeNames names the gRPC codes in the Twirp protocol.
+var codeNames = xerrs.RPCCodeNames{
+	Renamed: map[uint32]string{
+		1:  "canceled", // CANCELLED
+		15: "dataloss", // DATA_LOSS
+	},
+	// Twirp codes without a gRPC equivalent.
+	Extra: map[string]uint32{
+		"malformed": 3, // invalid_argument
+		"bad_route": 5, // not_found
+	},
+}
+
+// httpStatuses maps Twirp codes to HTTP statuses.
+var httpStatuses = map[string]int{
+	"canceled":            http.StatusRequestTimeout,
+	"unknown":             http.StatusInternalServerError,
+	"invalid_argument":    http.StatusBadRequest,
+	"malformed":           http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusRequestTimeout,
+	"not_found":           http.StatusNotFound,
+	"bad_route":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"unauthenticated":     http.StatusUnauthorized,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusPreconditionFailed,
+	"aborted":             http.StatusConflict,
+	"out_of_range":        http.StatusBadRequest,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"dataloss":            http.StatusInternalServerError,
+}
+
+// Encode converts err to the Twirp error format. The code comes from the
+// error type, as for gRPC; the error code, type, HTTP status, Details and
+// RetryAfter are carried in meta. It returns nil for a nil err.
+func Encode(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	appErr := xerrs.ToAppError(err)
+	e := &Error{
+		Code: codeNames.Name(appErr.GRPCCode()),
+		Msg:  appErr.Message,
+		Meta: map[string]string{
+			MetaErrorCode:  appErr.Code,
+			MetaErrorType:  string(appErr.Type),
+			MetaHTTPStatus: strconv.Itoa(appErr.GetHTTPStatus()),
+		},
+	}
+	if appErr.Details != "" {
+		e.Meta[MetaDetails] = appErr.Details
+	}
+	if d := appErr.RetryAfter(); d > 0 {
+		e.Meta[MetaRetryAfter] = d.String()
+	}
+	return e
+}
+
+// Decode converts a Twirp error to an *AppError. The type, code and HTTP
+// status are read from the meta added by Encode; errors from other servers
+// are classified by their Twirp code. It returns nil for a nil e.
+func Decode(e *Error) *xerrs.AppError {
+	if e == nil {
+		return nil
+	}
+	mapping := xerrs.GRPCCodeMapping(codeNames.Code(e.Code))
+	if t := e.Meta[MetaErrorType]; t != "" {
+		mapping.Type = xerrs.ErrorType(t)
+	}
+	if code := e.Meta[MetaErrorCode]; code != "" {
+		mapping.Code = code
+	}
+	httpStatus, _ := strconv.Atoi(e.Meta[MetaHTTPStatus])
+	// A zero status keeps the default status of the type.
+	appErr := xerrs.NewAppError(mapping.Type, mapping.Code, e.Msg).
+		WithHTTPStatus(httpStatus).
+		WithDetails(e.Meta[MetaDetails])
+	if d, err := time.ParseDuration(e.Meta[MetaRetryAfter]); err == nil && d > 0 {
+		appErr.WithRetryAfter(d)
+	}
+	return appErr
+}
+
+// Unmarshal decodes a Twirp JSON error body into an *AppError.
+func Unmarshal(data []byte) (*xerrs.AppError, error) {
+	var e Error
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, xerrs.Wrap(err, "decode twirp error").AsInvalidFormat()
+	}
+	return Decode(&e), nil
+}
+
+// WriteError writes err as a Twirp error response, with the HTTP status of
+// its Twirp code.
+func WriteError(w http.ResponseWriter, err error) {
+	e := Encode(err)
+	if e == nil {
+		e = Encode(xerrs.New(http.StatusText(http.StatusInternalServerError)))
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(HTTPStatus(e.Code))
+	_ = json.NewEncoder(w).Encode(e)
+}
+
+// HTTPStatus returns the HTTP status for a Twirp code.
+func HTTPStatus(code string) int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
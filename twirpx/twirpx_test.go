@@ -0,0 +1,116 @@
+package twirpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotfixfirst/go-xerrs"
+)
+
+func TestRoundTripAllTypes(t *testing.T) {
+	for _, errorType := range xerrs.RegisteredErrorTypes() {
+		t.Run(string(errorType), func(t *testing.T) {
+			original := xerrs.NewAppError(errorType, "SOME_CODE", "something failed").
+				WithDetails("more context").
+				WithRetryAfter(1500 * time.Millisecond)
+
+			data, err := json.Marshal(Encode(original))
+			require.NoError(t, err)
+			decoded, err := Unmarshal(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, errorType, decoded.Type)
+			assert.Equal(t, "SOME_CODE", decoded.Code)
+			assert.Equal(t, "something failed", decoded.Message)
+			assert.Equal(t, "more context", decoded.Details)
+			assert.Equal(t, original.GetHTTPStatus(), decoded.GetHTTPStatus())
+			assert.Equal(t, 1500*time.Millisecond, decoded.RetryAfter())
+			assert.Equal(t, codeNames.Name(original.GRPCCode()), Encode(decoded).Code)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	e := Encode(xerrs.NewAppError(xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound, "user not found"))
+	assert.Equal(t, &Error{
+		Code: "not_found",
+		Msg:  "user not found",
+		Meta: map[string]string{
+			MetaErrorCode:  xerrs.CodeResourceNotFound,
+			MetaErrorType:  string(xerrs.ErrorTypeNotFound),
+			MetaHTTPStatus: "404",
+		},
+	}, e)
+
+	assert.Equal(t, "already_exists", Encode(errors.New("duplicate key value")).Code)
+	assert.Equal(t, "dataloss", codeNames.Name(15))
+	assert.Nil(t, Encode(nil))
+}
+
+func TestDecodeForeign(t *testing.T) {
+	tests := []struct {
+		body     string
+		wantType xerrs.ErrorType
+		wantCode string
+	}{
+		{`{"code":"invalid_argument","msg":"bad"}`, xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput},
+		{`{"code":"malformed","msg":"bad"}`, xerrs.ErrorTypeValidation, xerrs.CodeInvalidInput},
+		{`{"code":"bad_route","msg":"bad"}`, xerrs.ErrorTypeNotFound, xerrs.CodeResourceNotFound},
+		{`{"code":"permission_denied","msg":"bad"}`, xerrs.ErrorTypeAuthorization, xerrs.CodeAccessDenied},
+		{`{"code":"resource_exhausted","msg":"bad"}`, xerrs.ErrorTypeRateLimit, xerrs.CodeRateLimitExceeded},
+		{`{"code":"dataloss","msg":"bad"}`, xerrs.ErrorTypeInternal, xerrs.CodeInternalError},
+		{`{"code":"bogus","msg":"bad"}`, xerrs.ErrorTypeInternal, xerrs.CodeInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			appErr, err := Unmarshal([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, appErr.Type)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, "bad", appErr.Message)
+			assert.Equal(t, tt.wantType.DefaultHTTPStatus(), appErr.GetHTTPStatus())
+		})
+	}
+
+	_, err := Unmarshal([]byte("not json"))
+	require.Error(t, err)
+	assert.Nil(t, Decode(nil))
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, xerrs.NewAppError(xerrs.ErrorTypePreconditionFailed, xerrs.CodePreconditionFailed, "etag mismatch"))
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	var body Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "failed_precondition", body.Code)
+	assert.Equal(t, "etag mismatch", body.Msg)
+}
+
+func TestCodeNames(t *testing.T) {
+	tests := []struct {
+		code uint32
+		name string
+	}{
+		{1, "canceled"},
+		{3, "invalid_argument"},
+		{15, "dataloss"},
+		{16, "unauthenticated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, codeNames.Name(tt.code))
+			assert.Equal(t, tt.code, codeNames.Code(tt.name))
+		})
+	}
+}
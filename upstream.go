@@ -0,0 +1,143 @@
+package xerrs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upstream identifies the dependency that caused an error.
+type Upstream struct {
+	// Service is the name of the upstream service, such as "payments".
+	Service string
+	// Endpoint is the operation or URL called, such as "POST /v1/charges".
+	Endpoint string
+	// Status is the status returned by the upstream, if any.
+	Status int
+	// Latency is how long the call took before failing.
+	Latency time.Duration
+}
+
+// String returns a one-line description such as
+// "payments POST /v1/charges (status 503, 1.2s)".
+func (u Upstream) String() string {
+	var b strings.Builder
+	b.WriteString(u.Service)
+	if u.Endpoint != "" {
+		b.WriteString(" ")
+		b.WriteString(u.Endpoint)
+	}
+	var extra []string
+	if u.Status != 0 {
+		extra = append(extra, fmt.Sprintf("status %d", u.Status))
+	}
+	if u.Latency > 0 {
+		extra = append(extra, u.Latency.String())
+	}
+	if len(extra) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(extra, ", "))
+	}
+	return b.String()
+}
+
+// WithUpstream records the upstream dependency that caused the error.
+func (e *AppError) WithUpstream(u Upstream) *AppError {
+	if e == nil {
+		return nil
+	}
+	e.upstream = &u
+	return e
+}
+
+// Upstream returns the upstream set with WithUpstream.
+func (e *AppError) Upstream() (Upstream, bool) {
+	if e == nil || e.upstream == nil {
+		return Upstream{}, false
+	}
+	return *e.upstream, true
+}
+
+// UpstreamFailures is the number of failures attributed to an upstream endpoint.
+type UpstreamFailures struct {
+	Service    string    `json:"service"`
+	Endpoint   string    `json:"endpoint,omitempty"`
+	Count      int64     `json:"count"`
+	LastStatus int       `json:"last_status,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// upstreamKey identifies an upstream endpoint in an UpstreamCounter.
+type upstreamKey struct {
+	service  string
+	endpoint string
+}
+
+// UpstreamCounter aggregates failure counts per upstream service and
+// endpoint. It is safe for concurrent use.
+type UpstreamCounter struct {
+	mu     sync.Mutex
+	counts map[upstreamKey]*UpstreamFailures
+	now    func() time.Time
+}
+
+// NewUpstreamCounter creates an empty UpstreamCounter.
+func NewUpstreamCounter() *UpstreamCounter {
+	return &UpstreamCounter{counts: make(map[upstreamKey]*UpstreamFailures), now: time.Now}
+}
+
+// Record counts err against its upstream. It reports false, and counts
+// nothing, when err carries no upstream.
+func (c *UpstreamCounter) Record(err error) bool {
+	appErr, ok := AsAppError(err)
+	if !ok {
+		return false
+	}
+	u, ok := appErr.Upstream()
+	if !ok {
+		return false
+	}
+	key := upstreamKey{service: u.Service, endpoint: u.Endpoint}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.counts[key]
+	if !ok {
+		failures = &UpstreamFailures{Service: u.Service, Endpoint: u.Endpoint}
+		c.counts[key] = failures
+	}
+	failures.Count++
+	failures.LastSeen = c.now()
+	if u.Status != 0 {
+		failures.LastStatus = u.Status
+	}
+	return true
+}
+
+// Counts returns the failure counts, most frequent first.
+func (c *UpstreamCounter) Counts() []UpstreamFailures {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make([]UpstreamFailures, 0, len(c.counts))
+	for _, failures := range c.counts {
+		counts = append(counts, *failures)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Service != counts[j].Service {
+			return counts[i].Service < counts[j].Service
+		}
+		return counts[i].Endpoint < counts[j].Endpoint
+	})
+	return counts
+}
+
+// Reset clears all counts.
+func (c *UpstreamCounter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = make(map[upstreamKey]*UpstreamFailures)
+}
@@ -0,0 +1,126 @@
+package xerrs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testUpstream = Upstream{
+	Service:  "payments",
+	Endpoint: "POST /v1/charges",
+	Status:   503,
+	Latency:  1200 * time.Millisecond,
+}
+
+func withDebugMode(tb testing.TB, enabled bool) {
+	tb.Helper()
+	previous := DebugMode()
+	SetDebugMode(enabled)
+	tb.Cleanup(func() { SetDebugMode(previous) })
+}
+
+func TestUpstream_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		upstream Upstream
+		expected string
+	}{
+		{"Full", testUpstream, "payments POST /v1/charges (status 503, 1.2s)"},
+		{"Service Only", Upstream{Service: "email"}, "email"},
+		{"Latency Only", Upstream{Service: "email", Latency: time.Second}, "email (1s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.upstream.String())
+		})
+	}
+}
+
+func TestWithUpstream(t *testing.T) {
+	err := New("charge failed").AsServiceTimeout().WithUpstream(testUpstream)
+
+	u, ok := err.Upstream()
+	require.True(t, ok)
+	assert.Equal(t, testUpstream, u)
+
+	wrapped, ok := Wrap(err, "checkout").Upstream()
+	require.True(t, ok)
+	assert.Equal(t, testUpstream, wrapped)
+
+	_, ok = New("x").Upstream()
+	assert.False(t, ok)
+
+	var nilErr *AppError
+	assert.Nil(t, nilErr.WithUpstream(testUpstream))
+}
+
+func TestUpstream_Format(t *testing.T) {
+	err := New("charge failed").AsServiceTimeout().WithUpstream(testUpstream)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "upstream: payments POST /v1/charges (status 503, 1.2s)")
+	assert.NotContains(t, fmt.Sprintf("%v", err), "payments")
+}
+
+func TestUpstream_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("failed", "error", New("charge failed").AsServiceTimeout().WithUpstream(testUpstream))
+
+	line := decodeLogLine(t, &buf)
+	group := line["error"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"service":  "payments",
+		"endpoint": "POST /v1/charges",
+		"status":   float64(503),
+		"latency":  float64(1200 * time.Millisecond),
+	}, group[LogKeyUpstream])
+}
+
+func TestUpstream_JSONOnlyInDebugMode(t *testing.T) {
+	err := New("charge failed").AsServiceTimeout().WithUpstream(testUpstream)
+
+	withDebugMode(t, false)
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+	assert.NotContains(t, string(data), "upstream")
+
+	SetDebugMode(true)
+	data, marshalErr = json.Marshal(err)
+	require.NoError(t, marshalErr)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, map[string]any{
+		"service":    "payments",
+		"endpoint":   "POST /v1/charges",
+		"status":     float64(503),
+		"latency_ms": float64(1200),
+	}, out["upstream"])
+}
+
+func TestUpstreamCounter(t *testing.T) {
+	counter := NewUpstreamCounter()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	counter.now = func() time.Time { return now }
+
+	email := Upstream{Service: "email", Endpoint: "send"}
+	assert.True(t, counter.Record(New("x").WithUpstream(testUpstream)))
+	assert.True(t, counter.Record(New("x").WithUpstream(email)))
+	assert.True(t, counter.Record(Wrap(New("x").WithUpstream(testUpstream), "retry")))
+	assert.False(t, counter.Record(New("no upstream")))
+	assert.False(t, counter.Record(fmt.Errorf("plain")))
+
+	assert.Equal(t, []UpstreamFailures{
+		{Service: "payments", Endpoint: "POST /v1/charges", Count: 2, LastStatus: 503, LastSeen: now},
+		{Service: "email", Endpoint: "send", Count: 1, LastSeen: now},
+	}, counter.Counts())
+
+	counter.Reset()
+	assert.Empty(t, counter.Counts())
+}